@@ -2,17 +2,24 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/glmt"
 )
 
@@ -23,73 +30,987 @@ func showVersion(cmd *cobra.Command, logger zerolog.Logger, out io.StringWriter)
 }
 
 func createMR(cmd *cobra.Command, logger zerolog.Logger, out io.StringWriter) {
-	flags := cmd.Flags()
-	cfg, err := finalConfig(flags)
+	ctx, cfg, core := startCore(cmd, logger, out)
+	params := createMRParams(cmd.Flags(), cfg, out)
+	params.TargetBranch = resolveTarget(ctx, cmd.Flags(), cfg, core, params.BranchRegexp, out)
+
+	mr, err := core.CreateMR(ctx, params)
 	if err != nil {
-		_, _ = out.WriteString("Failed to read config: " + err.Error() + "\n")
+		_, _ = out.WriteString("Failed to create MR: " + err.Error() + "\n")
+		os.Exit(exitCode(err))
+	}
+
+	switch mr.Status {
+	case glmt.MRStatusUpdated:
+		_, _ = out.WriteString("MR already exists, updated\n")
+	case glmt.MRStatusExisting:
+		_, _ = out.WriteString("MR already exists\n")
+	default:
+		if cfg.MR.Draft {
+			_, _ = out.WriteString("Draft MR created\n")
+		} else {
+			_, _ = out.WriteString("MR created\n")
+		}
+	}
+	_, _ = out.WriteString(mr.URL + "\n")
+}
+
+// createMRParams reads params of MR creation from config and flags of create command.
+// It exits on any error.
+func createMRParams(flags *pflag.FlagSet, cfg *config.Config, out io.StringWriter) glmt.CreateMRParams {
+	params := configMRParams(cfg, out)
+
+	na, err := flags.GetString("notification_message")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse notification_message: " + err.Error() + "\n")
 		os.Exit(1)
 	}
 
-	ll, err := parseLogLevel(flags)
+	nh, err := flags.GetBool("no_hooks")
 	if err != nil {
-		_, _ = out.WriteString("Failed to parse log level: " + err.Error() + "\n")
+		_, _ = out.WriteString("Failed to parse no_hooks: " + err.Error() + "\n")
 		os.Exit(1)
 	}
 
-	logger = logger.Level(ll)
-	ctx := logger.WithContext(context.Background())
+	rn, err := flags.GetBool("renotify")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse renotify: " + err.Error() + "\n")
+		os.Exit(1)
+	}
 
-	logger.Debug().Interface("config", cfg).Msg("final config")
+	tn, err := flags.GetString("template")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse template: " + err.Error() + "\n")
+		os.Exit(1)
+	}
 
-	dryRun, err := flags.GetBool("dryrun")
+	edit, err := flags.GetBool("edit")
 	if err != nil {
-		_, _ = out.WriteString("Failed to parse dryrun: " + err.Error() + "\n")
+		_, _ = out.WriteString("Failed to parse edit: " + err.Error() + "\n")
 		os.Exit(1)
 	}
 
-	core, err := createCore(dryRun, out, cfg)
+	params.Labels.Add, err = flags.GetStringSlice("label")
 	if err != nil {
-		_, _ = out.WriteString("Failed to start glmt: " + err.Error() + "\n")
+		_, _ = out.WriteString("Failed to parse label: " + err.Error() + "\n")
 		os.Exit(1)
 	}
 
-	br, err := regexp.Compile(cfg.MR.BranchRegexp)
+	params.Labels.Remove, err = flags.GetStringSlice("remove_label")
 	if err != nil {
-		_, _ = out.WriteString("Failed to compile branch regexp: " + err.Error() + "\n")
+		_, _ = out.WriteString("Failed to parse remove_label: " + err.Error() + "\n")
 		os.Exit(1)
 	}
 
-	na, err := flags.GetString("notification_message")
+	params.NotificationMessage = na
+	params.IgnoreHooks = nh
+	params.Renotify = rn
+	params.Template = tn
+	params.Edit = edit
+
+	applyDescriptionFile(flags, &params, out)
+
+	return params
+}
+
+// applyDescriptionFile sets description template from description_file flag,
+// description from file is preferred over MR templates. It exits on any error.
+func applyDescriptionFile(flags *pflag.FlagSet, params *glmt.CreateMRParams, out io.StringWriter) {
+	df, err := flags.GetString("description_file")
 	if err != nil {
-		_, _ = out.WriteString("Failed to parse notification_message: " + err.Error() + "\n")
+		_, _ = out.WriteString("Failed to parse description_file: " + err.Error() + "\n")
 		os.Exit(1)
 	}
 
-	nh, err := flags.GetBool("no_hooks")
+	// editor needs terminal's stdin
+	if df == "-" && params.Edit {
+		_, _ = out.WriteString("Failed to read description file: stdin can not be used with editor\n")
+		os.Exit(1)
+	}
+
+	if df == "" {
+		return
+	}
+
+	d, err := readDescriptionFile(df)
 	if err != nil {
-		_, _ = out.WriteString("Failed to parse no_hooks: " + err.Error() + "\n")
+		_, _ = out.WriteString("Failed to read description file: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	params.DescriptionTemplate = d
+	params.Template = ""
+	params.ProjectTemplates = nil
+}
+
+// resolveTarget chooses target branch by target rules and prints it with the reason,
+// target flag overrides rules. It exits on any error.
+func resolveTarget(
+	ctx context.Context,
+	flags *pflag.FlagSet,
+	cfg *config.Config,
+	core *glmt.Core,
+	br *regexp.Regexp,
+	out io.StringWriter,
+) string {
+	tp := glmt.TargetParams{
+		Default:      cfg.MR.TargetBranch,
+		BranchRegexp: br,
+		Candidates:   cfg.MR.TargetCandidates,
+	}
+
+	if tp.Default == "" {
+		tp.Default = flags.Lookup("target").DefValue
+	}
+
+	// flag is already applied to config as default target
+	if !flags.Changed("target") {
+		for _, r := range cfg.MR.TargetRules {
+			tp.Rules = append(tp.Rules, glmt.TargetRule{
+				Branch: r.Branch,
+				Var:    r.Var,
+				Value:  r.Value,
+				Target: r.Target,
+			})
+		}
+	}
+
+	t, err := core.ResolveTarget(ctx, tp)
+	if err != nil {
+		_, _ = out.WriteString("Failed to choose target branch: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	_, _ = out.WriteString("Target branch: " + t.Branch + " (" + t.Reason + ")\n")
+
+	return t.Branch
+}
+
+// readDescriptionFile reads file with MR description, "-" means stdin.
+func readDescriptionFile(name string) (string, error) {
+	if name == "-" {
+		d, err := ioutil.ReadAll(os.Stdin)
+		return string(d), err
+	}
+
+	d, err := ioutil.ReadFile(name)
+	return string(d), err
+}
+
+// configMRParams reads params of MR creation from config. It exits on any error.
+func configMRParams(cfg *config.Config, out io.StringWriter) glmt.CreateMRParams {
+	br, err := regexp.Compile(cfg.MR.BranchRegexp)
+	if err != nil {
+		_, _ = out.WriteString("Failed to compile branch regexp: " + err.Error() + "\n")
 		os.Exit(1)
 	}
 
-	params := glmt.CreateMRParams{
+	lp := glmt.LabelsParams{
+		Static:   cfg.MR.Labels.Static,
+		Map:      cfg.MR.Labels.Map,
+		Validate: cfg.MR.Labels.Validate,
+		Strict:   cfg.MR.Labels.Strict,
+	}
+
+	for _, r := range cfg.MR.Labels.BranchRules {
+		re, err := regexp.Compile(r.Regexp)
+		if err != nil {
+			_, _ = out.WriteString("Failed to compile label rule regexp: " + err.Error() + "\n")
+			os.Exit(1)
+		}
+
+		lp.BranchRules = append(lp.BranchRules, glmt.LabelRule{Regexp: re, Labels: r.Labels})
+	}
+
+	return glmt.CreateMRParams{
 		TargetBranch:        cfg.MR.TargetBranch,
 		BranchRegexp:        br,
 		TitleTemplate:       cfg.MR.Title,
 		DescriptionTemplate: cfg.MR.Description,
 		Squash:              cfg.MR.Squash,
 		RemoveBranch:        cfg.MR.RemoveSourceBranch,
-		NotificationMessage: na,
 		MentionsCount:       cfg.Mentioner.MentionsCount,
 		LabelVars:           cfg.MR.LabelVars,
-		IgnoreHooks:         nh,
+		Labels:              lp,
+		ExistingMR:          cfg.MR.Existing,
+		MentionMode:         cfg.Mentioner.Mode,
+		Draft:               cfg.MR.Draft,
+		Push:                cfg.MR.Push,
+		Checks: glmt.ChecksParams{
+			CleanWorktree:   cfg.Checks.CleanWorktree,
+			UpToDate:        cfg.Checks.UpToDate,
+			BehindTarget:    cfg.Checks.BehindTarget,
+			MaxBehindTarget: cfg.Checks.MaxBehindTarget,
+			NoFixupCommits:  cfg.Checks.NoFixupCommits,
+		},
+		Issue: glmt.IssueParams{
+			Var:           cfg.MR.Issue.Var,
+			Close:         cfg.MR.Issue.Close,
+			CopyLabels:    cfg.MR.Issue.CopyLabels,
+			CopyMilestone: cfg.MR.Issue.CopyMilestone,
+		},
+		Jira: glmt.JiraParams{
+			Var:          cfg.Jira.Var,
+			TransitionTo: cfg.Jira.TransitionTo,
+		},
+		ProjectTemplates: cfg.MR.Templates,
+		LenientTemplates: cfg.Templating.Lenient,
 	}
+}
 
-	mr, err := core.CreateMR(ctx, params)
+func createStack(cmd *cobra.Command, logger zerolog.Logger, out io.StringWriter) {
+	ctx, cfg, core := startCore(cmd, logger, out)
+	params := createMRParams(cmd.Flags(), cfg, out)
+	params.TargetBranch = resolveTarget(ctx, cmd.Flags(), cfg, core, params.BranchRegexp, out)
+
+	smrs, err := core.CreateStack(ctx, params)
+	writeStack(out, smrs)
 	if err != nil {
-		_, _ = out.WriteString("Failed to create MR: " + err.Error() + "\n")
+		_, _ = out.WriteString("Failed to create stack: " + err.Error() + "\n")
+		os.Exit(exitCode(err))
+	}
+}
+
+func retargetStack(cmd *cobra.Command, logger zerolog.Logger, out io.StringWriter) {
+	ctx, cfg, core := startCore(cmd, logger, out)
+
+	smrs, err := core.RetargetStack(ctx, glmt.RetargetParams{
+		TargetBranch: cfg.MR.TargetBranch,
+	})
+	writeStack(out, smrs)
+	if err != nil {
+		_, _ = out.WriteString("Failed to retarget stack: " + err.Error() + "\n")
+		os.Exit(exitCode(err))
+	}
+
+	if len(smrs) == 0 {
+		_, _ = out.WriteString("No MRs to retarget\n")
+	}
+}
+
+func writeStack(out io.StringWriter, smrs []glmt.StackMR) {
+	if len(smrs) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(stringWriter{out}, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "BRANCH\tTARGET\tSTATUS\tURL")
+	for _, smr := range smrs {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", smr.Branch, smr.TargetBranch, smr.Status, smr.URL)
+	}
+	_ = w.Flush()
+}
+
+func backportMR(cmd *cobra.Command, logger zerolog.Logger, out io.StringWriter) {
+	flags := cmd.Flags()
+	ctx, cfg, core := startCore(cmd, logger, out)
+
+	targets, err := flags.GetStringSlice("to")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse to: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	iid, err := flags.GetInt64("mr")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse mr: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	target, err := flags.GetString("target")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse target: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	na, err := flags.GetString("notification_message")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse notification_message: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	nh, err := flags.GetBool("no_hooks")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse no_hooks: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	params := configMRParams(cfg, out)
+	params.NotificationMessage = na
+	params.IgnoreHooks = nh
+
+	bmrs, err := core.Backport(ctx, glmt.BackportParams{
+		Targets:             targets,
+		MR:                  iid,
+		TargetBranch:        target,
+		TitleTemplate:       cfg.MR.Backport.Title,
+		DescriptionTemplate: cfg.MR.Backport.Description,
+		CreateMR:            params,
+	})
+	writeBackport(out, bmrs)
+	if err != nil {
+		_, _ = out.WriteString("Failed to backport: " + err.Error() + "\n")
+		os.Exit(exitCode(err))
+	}
+}
+
+func writeBackport(out io.StringWriter, bmrs []glmt.BackportMR) {
+	if len(bmrs) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(stringWriter{out}, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TARGET\tBRANCH\tSTATUS\tURL")
+	for _, bmr := range bmrs {
+		u := bmr.URL
+		if bmr.Error != "" {
+			u = bmr.Error
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", bmr.TargetBranch, bmr.Branch, bmr.Status, u)
+	}
+	_ = w.Flush()
+}
+
+func readyMR(cmd *cobra.Command, logger zerolog.Logger, out io.StringWriter) {
+	flags := cmd.Flags()
+	ctx, cfg, core := startCore(cmd, logger, out)
+
+	br, err := regexp.Compile(cfg.MR.BranchRegexp)
+	if err != nil {
+		_, _ = out.WriteString("Failed to compile branch regexp: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	na, err := flags.GetString("notification_message")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse notification_message: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	target, err := flags.GetString("target")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse target: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	mr, err := core.Ready(ctx, glmt.ReadyParams{
+		TargetBranch:        target,
+		BranchRegexp:        br,
+		NotificationMessage: na,
+	})
+	if err != nil {
+		_, _ = out.WriteString("Failed to mark MR as ready: " + err.Error() + "\n")
+		os.Exit(exitCode(err))
+	}
+
+	if mr.Status == glmt.MRStatusExisting {
+		_, _ = out.WriteString("MR is not a draft, nothing is changed\n")
+	} else {
+		_, _ = out.WriteString("MR is ready\n")
+	}
+	_, _ = out.WriteString(mr.URL + "\n")
+}
+
+func mergeMR(cmd *cobra.Command, logger zerolog.Logger, out io.StringWriter) {
+	flags := cmd.Flags()
+	ctx, cfg, core := startCore(cmd, logger, out)
+
+	br, err := regexp.Compile(cfg.MR.BranchRegexp)
+	if err != nil {
+		_, _ = out.WriteString("Failed to compile branch regexp: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	target, err := flags.GetString("target")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse target: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	wps, err := flags.GetBool("when_pipeline_succeeds")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse when_pipeline_succeeds: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	force, err := flags.GetBool("force")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse force: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	nh, err := flags.GetBool("no_hooks")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse no_hooks: " + err.Error() + "\n")
 		os.Exit(1)
 	}
 
-	_, _ = out.WriteString("MR created\n")
+	mr, err := core.Merge(ctx, glmt.MergeParams{
+		TargetBranch:         target,
+		BranchRegexp:         br,
+		WhenPipelineSucceeds: wps,
+		SquashCommitTemplate: cfg.MR.SquashCommitMessage,
+		MergeCommitTemplate:  cfg.MR.MergeCommitMessage,
+		Squash:               cfg.MR.Squash,
+		RemoveBranch:         cfg.MR.RemoveSourceBranch,
+		Force:                force,
+		IgnoreHooks:          nh,
+		LenientTemplates:     cfg.Templating.Lenient,
+	})
+	if err != nil {
+		_, _ = out.WriteString("Failed to merge MR: " + err.Error() + "\n")
+		os.Exit(exitCode(err))
+	}
+
+	if mr.Status == glmt.MRStatusMergeScheduled {
+		_, _ = out.WriteString("MR will be merged when pipeline succeeds\n")
+	} else {
+		_, _ = out.WriteString("MR merged\n")
+	}
 	_, _ = out.WriteString(mr.URL + "\n")
 }
+
+func showStatus(cmd *cobra.Command, logger zerolog.Logger, out io.StringWriter) {
+	flags := cmd.Flags()
+	ctx, _, core := startCore(cmd, logger, out)
+
+	target, err := flags.GetString("target")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse target: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	watch, err := flags.GetBool("watch")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse watch: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	interval, err := flags.GetDuration("interval")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse interval: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	asJSON, err := flags.GetBool("json")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse json: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	var st glmt.BranchStatus
+	for {
+		st, err = core.Status(ctx, glmt.StatusParams{
+			TargetBranch: target,
+		})
+		if err != nil {
+			_, _ = out.WriteString("Failed to get MR status: " + err.Error() + "\n")
+			os.Exit(exitCode(err))
+		}
+
+		if !watch || st.PipelineFinished() {
+			break
+		}
+
+		if !asJSON {
+			_, _ = out.WriteString("Pipeline is " + st.Pipeline.Status + ", waiting...\n")
+		}
+		time.Sleep(interval)
+	}
+
+	if asJSON {
+		data, _ := json.MarshalIndent(st, "", "  ")
+		_, _ = out.WriteString(string(data) + "\n")
+	} else {
+		writeStatus(out, st)
+	}
+
+	if watch && st.PipelineFailed() {
+		os.Exit(exitPipelineFailed)
+	}
+}
+
+func writeStatus(out io.StringWriter, st glmt.BranchStatus) {
+	w := tabwriter.NewWriter(stringWriter{out}, 0, 0, 2, ' ', 0)
+
+	title := st.Title
+	if st.Draft {
+		title += " (draft)"
+	}
+	_, _ = fmt.Fprintf(w, "MR:\t!%d %s\n", st.IID, title)
+	_, _ = fmt.Fprintf(w, "URL:\t%s\n", st.URL)
+	_, _ = fmt.Fprintf(w, "State:\t%s\n", st.State)
+
+	if st.Pipeline != nil {
+		_, _ = fmt.Fprintf(w, "Pipeline:\t%s %s\n", st.Pipeline.Status, st.Pipeline.URL)
+		for _, j := range st.Pipeline.FailedJobs {
+			_, _ = fmt.Fprintf(w, "\tfailed job: %s / %s %s\n", j.Stage, j.Name, j.URL)
+		}
+	} else {
+		_, _ = fmt.Fprintf(w, "Pipeline:\tnone\n")
+	}
+
+	_, _ = fmt.Fprintf(w, "Approvals:\t%d/%d\n", st.ApprovalsGiven, st.ApprovalsRequired)
+	_, _ = fmt.Fprintf(w, "Unresolved discussions:\t%d\n", st.UnresolvedDiscussions)
+
+	if st.HasConflicts {
+		_, _ = fmt.Fprintf(w, "Conflicts:\tyes\n")
+	} else {
+		_, _ = fmt.Fprintf(w, "Conflicts:\tno\n")
+	}
+
+	if st.BehindTarget > 0 {
+		_, _ = fmt.Fprintf(w, "Target:\t%s, behind by %d commit(s)\n", st.TargetBranch, st.BehindTarget)
+	} else {
+		_, _ = fmt.Fprintf(w, "Target:\t%s, up to date\n", st.TargetBranch)
+	}
+
+	_ = w.Flush()
+}
+
+func reviewMRs(cmd *cobra.Command, logger zerolog.Logger, out io.StringWriter) {
+	flags := cmd.Flags()
+	ctx, _, core := startCore(cmd, logger, out)
+
+	var (
+		params glmt.ReviewParams
+		err    error
+	)
+
+	params.Project, err = flags.GetString("project")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse project: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	params.Labels, err = flags.GetStringSlice("label")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse label: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	params.OlderThan, err = flags.GetDuration("older_than")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse older_than: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	params.NewerThan, err = flags.GetDuration("newer_than")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse newer_than: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	mrs, err := core.ReviewMRs(ctx, params)
+	if err != nil {
+		_, _ = out.WriteString("Failed to list MRs: " + err.Error() + "\n")
+		os.Exit(exitCode(err))
+	}
+
+	if len(mrs) == 0 {
+		_, _ = out.WriteString("No MRs waiting for your review\n")
+		return
+	}
+
+	w := tabwriter.NewWriter(stringWriter{out}, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "TITLE\tAUTHOR\tPIPELINE\tAPPROVALS\tURL")
+	for _, mr := range mrs {
+		pipeline := mr.PipelineStatus
+		if pipeline == "" {
+			pipeline = "-"
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t@%s\t%s\t%d/%d\t%s\n",
+			mr.Title,
+			mr.Author,
+			pipeline,
+			mr.ApprovalsGiven,
+			mr.ApprovalsRequired,
+			mr.URL,
+		)
+	}
+	_ = w.Flush()
+}
+
+func showChangelog(cmd *cobra.Command, logger zerolog.Logger, out io.StringWriter) {
+	flags := cmd.Flags()
+	ctx, cfg, core := startCore(cmd, logger, out)
+
+	br, err := regexp.Compile(cfg.MR.BranchRegexp)
+	if err != nil {
+		_, _ = out.WriteString("Failed to compile branch regexp: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	from, err := flags.GetString("from")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse from: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	to, err := flags.GetString("to")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse to: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	target, err := flags.GetString("target")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse target: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	if target == "" {
+		target = cfg.Changelog.TargetBranch
+	}
+
+	tf, err := flags.GetString("template_file")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse template_file: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	release, err := flags.GetString("release")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse release: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	asJSON, err := flags.GetBool("json")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse json: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	tmpl := cfg.Changelog.Template
+	if tf != "" {
+		t, err := ioutil.ReadFile(tf)
+		if err != nil {
+			_, _ = out.WriteString("Failed to read template file: " + err.Error() + "\n")
+			os.Exit(1)
+		}
+
+		tmpl = string(t)
+	}
+
+	params := glmt.ChangelogParams{
+		From:         from,
+		To:           to,
+		TargetBranch: target,
+		BranchRegexp: br,
+		GroupVar:     cfg.Changelog.GroupVar,
+		OtherGroup:   cfg.Changelog.OtherGroup,
+	}
+
+	for _, g := range cfg.Changelog.Groups {
+		params.Groups = append(params.Groups, glmt.ChangelogGroupRule{
+			Title:  g.Title,
+			Labels: g.Labels,
+			Values: g.Values,
+		})
+	}
+
+	cl, err := core.Changelog(ctx, params)
+	if err != nil {
+		_, _ = out.WriteString("Failed to collect changelog: " + err.Error() + "\n")
+		os.Exit(exitCode(err))
+	}
+
+	notes, err := glmt.RenderChangelog(cl, tmpl)
+	if err != nil {
+		_, _ = out.WriteString("Failed to render changelog: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	if asJSON {
+		data, _ := json.MarshalIndent(cl, "", "  ")
+		_, _ = out.WriteString(string(data) + "\n")
+	} else {
+		_, _ = out.WriteString(notes)
+	}
+
+	if release == "" {
+		return
+	}
+
+	u, err := core.PostRelease(ctx, release, notes)
+	if err != nil {
+		_, _ = out.WriteString("Failed to post release notes: " + err.Error() + "\n")
+		os.Exit(exitCode(err))
+	}
+
+	_, _ = out.WriteString("Release notes posted\n" + u + "\n")
+}
+
+func listTemplates(cmd *cobra.Command, logger zerolog.Logger, out io.StringWriter) {
+	flags := cmd.Flags()
+	ctx, cfg, core := startCore(cmd, logger, out)
+
+	target, err := flags.GetString("target")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse target: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	asJSON, err := flags.GetBool("json")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse json: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	mt, err := core.MRTemplates(ctx, glmt.TemplatesParams{
+		TargetBranch:     target,
+		ProjectTemplates: cfg.MR.Templates,
+	})
+	if err != nil {
+		_, _ = out.WriteString("Failed to list MR templates: " + err.Error() + "\n")
+		os.Exit(exitCode(err))
+	}
+
+	if asJSON {
+		data, _ := json.MarshalIndent(mt, "", "  ")
+		_, _ = out.WriteString(string(data) + "\n")
+		return
+	}
+
+	if len(mt.Names) == 0 {
+		_, _ = out.WriteString("No MR templates found\n")
+		return
+	}
+
+	for _, n := range mt.Names {
+		if strings.EqualFold(n, mt.Default) {
+			n += " (default)"
+		}
+
+		_, _ = out.WriteString(n + "\n")
+	}
+}
+
+func renderTemplates(cmd *cobra.Command, logger zerolog.Logger, out io.StringWriter) {
+	flags := cmd.Flags()
+	ctx, cfg, core := startCore(cmd, logger, out)
+	params := configMRParams(cfg, out)
+
+	na, err := flags.GetString("notification_message")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse notification_message: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	tmpls, err := flags.GetStringArray("template")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse template: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	username, err := flags.GetString("username")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse username: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	params.Labels.Add, err = flags.GetStringSlice("label")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse label: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	params.Labels.Remove, err = flags.GetStringSlice("remove_label")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse remove_label: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	asJSON, err := flags.GetBool("json")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse json: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	// target branch is reported out of JSON
+	var tout io.StringWriter = out
+	if asJSON {
+		tout = os.Stderr
+	}
+
+	params.TargetBranch = resolveTarget(ctx, flags, cfg, core, params.BranchRegexp, tout)
+	params.NotificationMessage = na
+	applyDescriptionFile(flags, &params, out)
+
+	r, err := core.Render(ctx, glmt.RenderParams{
+		MR:        params,
+		Username:  username,
+		Templates: tmpls,
+	})
+	if err != nil {
+		_, _ = out.WriteString("Failed to render templates: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	if asJSON {
+		data, _ := json.MarshalIndent(r, "", "  ")
+		_, _ = out.WriteString(string(data) + "\n")
+		return
+	}
+
+	writeRendered(out, r)
+}
+
+func writeRendered(out io.StringWriter, r glmt.Rendered) {
+	names := make([]string, 0, len(r.Vars))
+	for n := range r.Vars {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	_, _ = out.WriteString("Variables:\n")
+	w := tabwriter.NewWriter(stringWriter{out}, 0, 0, 2, ' ', 0)
+	for _, n := range names {
+		_, _ = fmt.Fprintf(w, "  %s\t%s\n", n, varText(r.Vars[n]))
+	}
+	_ = w.Flush()
+
+	_, _ = out.WriteString("\nTitle:\n" + r.Title + "\n")
+	_, _ = out.WriteString("\nDescription:\n" + r.Description + "\n")
+	_, _ = out.WriteString("\nLabels: " + strings.Join(r.Labels, ", ") + "\n")
+
+	for _, m := range r.Notifications {
+		_, _ = out.WriteString("\nNotification " + m.Notifier + ":\n" + m.Text + "\n")
+	}
+
+	for _, t := range r.Templates {
+		_, _ = out.WriteString("\nTemplate " + t.Template + ":\n")
+		if t.Error != "" {
+			_, _ = out.WriteString("Error: " + t.Error + "\n")
+		} else {
+			_, _ = out.WriteString(t.Text + "\n")
+		}
+	}
+}
+
+// varText shows template variable in one line, commits are shown by subjects.
+func varText(v interface{}) string {
+	cs, ok := v.([]glmt.Commit)
+	if !ok {
+		return strings.ReplaceAll(fmt.Sprint(v), "\n", "\\n")
+	}
+
+	ss := make([]string, 0, len(cs))
+	for _, c := range cs {
+		ss = append(ss, c.ShortSHA+" "+c.Subject)
+	}
+
+	return "[" + strings.Join(ss, "; ") + "]"
+}
+
+// startCore reads config and flags common for all commands and creates glmt core.
+// It exits on any error.
+func startCore(cmd *cobra.Command, logger zerolog.Logger, out io.StringWriter) (context.Context, *config.Config, *glmt.Core) {
+	flags := cmd.Flags()
+	cfg, err := finalConfig(flags)
+	if err != nil {
+		_, _ = out.WriteString("Failed to read config: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	ll, err := parseLogLevel(flags)
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse log level: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	logger = logger.Level(ll)
+	ctx := logger.WithContext(context.Background())
+
+	logger.Debug().Interface("config", cfg).Msg("final config")
+
+	// render reports errors of templates itself
+	if !cfg.Templating.Lenient && cmd.Name() != "render" {
+		err = validateTemplates(cfg, commandTemplates[cmd.CommandPath()], logger)
+		if err != nil {
+			_, _ = out.WriteString("Failed to validate config: " + err.Error() + "\n")
+			os.Exit(1)
+		}
+	}
+
+	dryRun, err := flags.GetBool("dryrun")
+	if err != nil {
+		_, _ = out.WriteString("Failed to parse dryrun: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	// dry run does not request project's labels, so every label is unknown
+	if dryRun {
+		cfg.MR.Labels.Strict = false
+	}
+
+	core, err := createCore(dryRun, out, cfg)
+	if err != nil {
+		_, _ = out.WriteString("Failed to start glmt: " + err.Error() + "\n")
+		os.Exit(1)
+	}
+
+	return ctx, cfg, core
+}
+
+var notifierTemplates = []string{
+	"notifier.slack_web_hook.message",
+	"notifier.telegram.message",
+	"notifier.mattermost_web_hook.message",
+}
+
+// commandTemplates are config templates rendered by commands.
+var commandTemplates = map[string][]string{
+	"glmt create":       append([]string{"mr.title", "mr.description"}, notifierTemplates...),
+	"glmt stack create": append([]string{"mr.title", "mr.description"}, notifierTemplates...),
+	"glmt backport":     append([]string{"mr.backport.title", "mr.backport.description"}, notifierTemplates...),
+	"glmt ready":        notifierTemplates,
+	"glmt merge":        {"mr.squash_commit_message", "mr.merge_commit_message"},
+}
+
+// validateTemplates checks templates of config used by command, so typos are
+// reported before any request is made. Invalid templates of other commands
+// are only logged, they must not break commands not rendering them.
+func validateTemplates(cfg *config.Config, names []string, logger zerolog.Logger) error {
+	br, err := regexp.Compile(cfg.MR.BranchRegexp)
+	if err != nil {
+		return fmt.Errorf("can not compile branch regexp: %w", err)
+	}
+
+	all := map[string]string{
+		"mr.title":                             cfg.MR.Title,
+		"mr.description":                       cfg.MR.Description,
+		"mr.squash_commit_message":             cfg.MR.SquashCommitMessage,
+		"mr.merge_commit_message":              cfg.MR.MergeCommitMessage,
+		"mr.backport.title":                    cfg.MR.Backport.Title,
+		"mr.backport.description":              cfg.MR.Backport.Description,
+		"notifier.slack_web_hook.message":      cfg.Notifier.SlackWebHook.MessageTmpl,
+		"notifier.telegram.message":            cfg.Notifier.Telegram.MessageTmpl,
+		"notifier.mattermost_web_hook.message": cfg.Notifier.MattermostWebHook.MessageTmpl,
+	}
+
+	ut := make(map[string]string, len(names))
+	for _, n := range names {
+		ut[n] = all[n]
+		delete(all, n)
+	}
+
+	err = glmt.ValidateTemplates(br, all)
+	if err != nil {
+		logger.Warn().Err(err).Msg("templates of other commands are invalid")
+	}
+
+	return glmt.ValidateTemplates(br, ut)
+}
+
+// stringWriter adapts io.StringWriter to io.Writer.
+type stringWriter struct {
+	w io.StringWriter
+}
+
+func (sw stringWriter) Write(p []byte) (int, error) {
+	return sw.w.WriteString(string(p))
+}
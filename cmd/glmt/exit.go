@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/glmt"
+)
+
+// Exit codes, keep in sync with readme.
+const (
+	exitError          = 1
+	exitNotification   = 2
+	exitUnauthorized   = 3
+	exitForbidden      = 4
+	exitNotFound       = 5
+	exitMRExists       = 6
+	exitValidation     = 7
+	exitRateLimited    = 8
+	exitGitLabInternal = 9
+	exitNotMergeable   = 10
+	exitPipelineFailed = 11
+	exitNotPushed      = 12
+	exitChecksFailed   = 13
+	exitJiraTransition = 14
+	exitUnknownLabels  = 15
+	exitBackportFailed = 16
+)
+
+// exitCode maps error to exit code, so scripts can react on
+// errors without parsing output.
+func exitCode(err error) int {
+	// MR is created, but notification is failed
+	if errors.Is(err, glmt.ErrNotification) {
+		return exitNotification
+	}
+
+	// MR is created, but Jira issue is not moved
+	if errors.Is(err, glmt.ErrJiraTransition) {
+		return exitJiraTransition
+	}
+
+	if errors.Is(err, glmt.ErrNotMergeable) {
+		return exitNotMergeable
+	}
+
+	if errors.Is(err, glmt.ErrChecksFailed) {
+		return exitChecksFailed
+	}
+
+	if errors.Is(err, glmt.ErrUnknownLabels) {
+		return exitUnknownLabels
+	}
+
+	if errors.Is(err, glmt.ErrBackportFailed) {
+		return exitBackportFailed
+	}
+
+	if errors.Is(err, glmt.ErrNotPushed) {
+		return exitNotPushed
+	}
+
+	if errors.Is(err, glmt.ErrMRExists) {
+		return exitMRExists
+	}
+
+	if ge, ok := gitlab.AsGitlabError(err); ok {
+		switch ge.Kind() {
+		case gitlab.ErrorUnauthorized:
+			return exitUnauthorized
+		case gitlab.ErrorForbidden:
+			return exitForbidden
+		case gitlab.ErrorNotFound:
+			return exitNotFound
+		case gitlab.ErrorConflict:
+			return exitMRExists
+		case gitlab.ErrorValidation:
+			return exitValidation
+		case gitlab.ErrorRateLimited:
+			return exitRateLimited
+		case gitlab.ErrorServer:
+			return exitGitLabInternal
+		}
+	}
+
+	return exitError
+}
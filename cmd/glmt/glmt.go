@@ -1,18 +1,24 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	credentialsi "gitlab.com/gitlab-merge-tool/glmt/internal/credentials/impl"
+	editori "gitlab.com/gitlab-merge-tool/glmt/internal/editor/impl"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/git"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
 	gitlabi "gitlab.com/gitlab-merge-tool/glmt/internal/gitlab/impl"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/glmt"
 	hooksi "gitlab.com/gitlab-merge-tool/glmt/internal/hooks/impl"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/jira"
+	jirai "gitlab.com/gitlab-merge-tool/glmt/internal/jira/impl"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/notifier"
 	notifieri "gitlab.com/gitlab-merge-tool/glmt/internal/notifier/impl"
 	teami "gitlab.com/gitlab-merge-tool/glmt/internal/team/impl"
@@ -28,11 +34,13 @@ func main() {
 
 	var rootCmd = &cobra.Command{Use: "glmt"}
 	rootCmd.PersistentFlags().StringP("config", "c", "", "path to config")
-	rootCmd.PersistentFlags().StringP("token", "k", "", "gitlab API token (get it on /profile/personal_access_tokens page)")
+	rootCmd.PersistentFlags().StringP("token", "k", "", "gitlab API token (get it on /profile/personal_access_tokens page) or reference to it, like env:GITLAB_TOKEN")
 	rootCmd.PersistentFlags().StringP("host", "a", "", "gitlab host")
 	rootCmd.PersistentFlags().BoolP("dryrun", "y", false, "dry run true only shows request to gitlab, but do not sends them")
 	rootCmd.PersistentFlags().StringP("log", "l", "info", "log level")
 	rootCmd.PersistentFlags().Bool("no_hooks", false, "do not run hooks")
+	rootCmd.PersistentFlags().String("remote", "", "git remote of current branch (default is branch's tracking remote)")
+	rootCmd.PersistentFlags().String("target_remote", "", "git remote of project MRs are created to (default \"upstream\" if present)")
 
 	var cmdCreate = &cobra.Command{
 		Use:   "create",
@@ -42,13 +50,173 @@ func main() {
 			createMR(cmd, logger, out)
 		},
 	}
-	createFlags := cmdCreate.Flags()
-	createFlags.StringP("target", "b", "master", "Merge Request's target branch")
-	createFlags.StringP("title", "t", "", "Merge Request's title (template variables can be used in title)")
-	createFlags.StringP("description", "d", "", "Merge Request's description (template variables can be used in description)")
-	createFlags.StringP("notification_message", "n", "", "Additional notification message")
+	addCreateFlags(cmdCreate.Flags())
 	rootCmd.AddCommand(cmdCreate)
 
+	var cmdStack = &cobra.Command{
+		Use:   "stack",
+		Short: "Manage merge requests of stacked branches",
+		Long: `Works with stack of branches, where every branch is based on previous one
+and the first one is based on target branch.`,
+	}
+
+	var cmdStackCreate = &cobra.Command{
+		Use:   "create",
+		Short: "Create merge requests for stacked branches",
+		Long: `Creates or updates merge request for every branch current branch is stacked on
+and for current branch. Every merge request targets parent branch, description gets
+section with links to all merge requests of stack.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			createStack(cmd, logger, out)
+		},
+	}
+	addCreateFlags(cmdStackCreate.Flags())
+	cmdStack.AddCommand(cmdStackCreate)
+
+	var cmdStackRetarget = &cobra.Command{
+		Use:   "retarget",
+		Short: "Retarget merge requests of merged branches' children",
+		Long:  `Moves merge requests of stack targeting merged branches to targets of merged merge requests.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			retargetStack(cmd, logger, out)
+		},
+	}
+	cmdStackRetarget.Flags().StringP("target", "b", "master", "Base branch of stack")
+	cmdStack.AddCommand(cmdStackRetarget)
+	rootCmd.AddCommand(cmdStack)
+
+	var cmdBackport = &cobra.Command{
+		Use:   "backport",
+		Short: "Backport merge request to other branches",
+		Long: `Cherry-picks commits of current branch's merge request (or of merge request from --mr)
+onto every target branch as backport/<target>/<branch> branch, pushes it and creates merge request.
+Conflicting targets are reported and skipped.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			backportMR(cmd, logger, out)
+		},
+	}
+	backportFlags := cmdBackport.Flags()
+	backportFlags.StringSlice("to", nil, "Branch to backport to (can be repeated)")
+	backportFlags.Int64("mr", 0, "IID of merge request to backport (default is current branch's merge request)")
+	backportFlags.StringP("target", "b", "", "Merge Request's target branch (if there are several MRs for current branch)")
+	backportFlags.StringP("notification_message", "n", "", "Additional notification message")
+	_ = cmdBackport.MarkFlagRequired("to")
+	rootCmd.AddCommand(cmdBackport)
+
+	var cmdReady = &cobra.Command{
+		Use:   "ready",
+		Short: "Mark merge request as ready",
+		Long:  `Removes draft status from merge request of current branch and sends notifications.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			readyMR(cmd, logger, out)
+		},
+	}
+	readyFlags := cmdReady.Flags()
+	readyFlags.StringP("target", "b", "", "Merge Request's target branch (if there are several MRs for current branch)")
+	readyFlags.StringP("notification_message", "n", "", "Additional notification message")
+	rootCmd.AddCommand(cmdReady)
+
+	var cmdMerge = &cobra.Command{
+		Use:   "merge",
+		Short: "Merge merge request",
+		Long: `Merges merge request of current branch, or sets it to be merged when pipeline succeeds.
+Before merging checks conflicts, unresolved discussions and approvals and runs merge hooks.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			mergeMR(cmd, logger, out)
+		},
+	}
+	mergeFlags := cmdMerge.Flags()
+	mergeFlags.StringP("target", "b", "", "Merge Request's target branch (if there are several MRs for current branch)")
+	mergeFlags.BoolP("when_pipeline_succeeds", "w", false, "Merge when pipeline succeeds")
+	mergeFlags.BoolP("force", "f", false, "Do not check conflicts, discussions and approvals")
+	rootCmd.AddCommand(cmdMerge)
+
+	var cmdStatus = &cobra.Command{
+		Use:   "status",
+		Short: "Show status of current branch's merge request",
+		Long:  `Shows pipeline, approvals, unresolved discussions and conflicts of current branch's merge request.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			showStatus(cmd, logger, out)
+		},
+	}
+	statusFlags := cmdStatus.Flags()
+	statusFlags.StringP("target", "b", "", "Merge Request's target branch (if there are several MRs for current branch)")
+	statusFlags.BoolP("watch", "w", false, "Wait until pipeline finishes, exit with error if pipeline fails")
+	statusFlags.Duration("interval", 15*time.Second, "Pipeline polling interval for watch mode")
+	statusFlags.Bool("json", false, "Print status as JSON")
+	rootCmd.AddCommand(cmdStatus)
+
+	var cmdReview = &cobra.Command{
+		Use:   "review",
+		Short: "List merge requests waiting for your approval",
+		Long:  `Lists opened merge requests where you are reviewer or assignee or where you were mentioned.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			reviewMRs(cmd, logger, out)
+		},
+	}
+	reviewFlags := cmdReview.Flags()
+	reviewFlags.StringP("project", "p", "", "Show only MRs of project (path with namespace)")
+	reviewFlags.StringSlice("label", nil, "Show only MRs with label (can be repeated)")
+	reviewFlags.Duration("older_than", 0, "Show only MRs created earlier than specified time ago (e.g. 24h)")
+	reviewFlags.Duration("newer_than", 0, "Show only MRs created later than specified time ago (e.g. 24h)")
+	rootCmd.AddCommand(cmdReview)
+
+	var cmdTemplates = &cobra.Command{
+		Use:   "templates",
+		Short: "List merge request templates",
+		Long: `Lists merge request templates of current project from .gitlab/merge_request_templates
+of local checkout or, if there are none, of project in GitLab.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			listTemplates(cmd, logger, out)
+		},
+	}
+	templatesFlags := cmdTemplates.Flags()
+	templatesFlags.StringP("target", "b", "", "Branch templates are read from GitLab at (default is project's default branch)")
+	templatesFlags.Bool("json", false, "Print templates as JSON")
+	rootCmd.AddCommand(cmdTemplates)
+
+	var cmdChangelog = &cobra.Command{
+		Use:   "changelog",
+		Short: "Generate changelog from merged merge requests",
+		Long: `Collects merge requests merged between two git revisions, groups them by labels or
+by branch_regexp group and renders them with template. Notes can be posted to GitLab release.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			showChangelog(cmd, logger, out)
+		},
+	}
+	changelogFlags := cmdChangelog.Flags()
+	changelogFlags.String("from", "", "Revision changelog starts after, like previous release tag (default is the first commit)")
+	changelogFlags.String("to", "HEAD", "Revision changelog ends at")
+	changelogFlags.StringP("target", "b", "", "List only MRs merged into branch")
+	changelogFlags.String("template_file", "", "File with changelog template (default is changelog.template config)")
+	changelogFlags.String("release", "", "Post changelog as notes of GitLab release of tag")
+	changelogFlags.Bool("json", false, "Print changelog as JSON")
+	rootCmd.AddCommand(cmdChangelog)
+
+	var cmdRender = &cobra.Command{
+		Use:   "render",
+		Short: "Preview templates for current branch",
+		Long: `Prints template variables of current branch and renders MR title, description,
+labels and notification messages with them, as create command would. Nothing but
+MR templates missing in checkout is requested from GitLab and no notifications are sent.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			renderTemplates(cmd, logger, out)
+		},
+	}
+	renderFlags := cmdRender.Flags()
+	renderFlags.StringP("target", "b", "master", "Merge Request's target branch, \"auto\" or glob of remote branches (default is chosen by target rules)")
+	renderFlags.StringP("title", "t", "", "Title template (default is mr.title config)")
+	renderFlags.StringP("description", "d", "", "Description template (default is mr.description config)")
+	renderFlags.String("description_file", "", "File with description template, \"-\" reads stdin")
+	renderFlags.Bool("draft", false, "Render title of draft MR")
+	renderFlags.StringSlice("label", nil, "Add label to MR (can be repeated)")
+	renderFlags.StringSlice("remove_label", nil, "Remove label from MR (can be repeated)")
+	renderFlags.StringP("notification_message", "n", "", "Additional notification message")
+	renderFlags.StringArray("template", nil, "Additional template to render (can be repeated)")
+	renderFlags.String("username", "", "GitLab username used in variables and mentions")
+	renderFlags.Bool("json", false, "Print variables and rendered texts as JSON")
+	rootCmd.AddCommand(cmdRender)
+
 	var cmdVersion = &cobra.Command{
 		Use:   "version",
 		Short: "Show GLMT version",
@@ -62,6 +230,23 @@ func main() {
 	_ = rootCmd.Execute()
 }
 
+// addCreateFlags defines flags of commands creating MR.
+func addCreateFlags(flags *pflag.FlagSet) {
+	flags.StringP("target", "b", "master", "Merge Request's target branch, \"auto\" or glob of remote branches (default is chosen by target rules)")
+	flags.StringP("title", "t", "", "Merge Request's title (template variables can be used in title)")
+	flags.StringP("description", "d", "", "Merge Request's description (template variables can be used in description)")
+	flags.StringP("notification_message", "n", "", "Additional notification message")
+	flags.String("existing", "", "What to do if MR for the branch already exists: update, keep or fail (default \"update\")")
+	flags.Bool("renotify", false, "Send notifications even if MR already exists")
+	flags.Bool("draft", false, "Create MR as draft, notifications will be sent by ready command")
+	flags.Bool("push", false, "Push current branch and set its upstream before creating MR")
+	flags.String("template", "", "Name of MR template from .gitlab/merge_request_templates used as description")
+	flags.StringSlice("label", nil, "Add label to MR (can be repeated)")
+	flags.StringSlice("remove_label", nil, "Remove label from MR (can be repeated)")
+	flags.BoolP("edit", "e", false, "Edit title, description, labels, target branch and reviewers in editor before creating MR")
+	flags.String("description_file", "", "File with Merge Request's description (template variables can be used), \"-\" reads stdin (not with --edit)")
+}
+
 func parseLogLevel(flags *pflag.FlagSet) (zerolog.Level, error) {
 	log, err := flags.GetString("log")
 	if err != nil {
@@ -106,9 +291,49 @@ func finalConfig(flags *pflag.FlagSet) (*config.Config, error) {
 		return nil, fmt.Errorf("can not parse flags: %w", err)
 	}
 
+	err = resolveSecrets(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
+// resolveSecrets replaces references to secrets (like "env:GITLAB_TOKEN") with their
+// values. Secrets of disabled notifiers are not resolved.
+func resolveSecrets(ctx context.Context, cfg *config.Config) error {
+	r := credentialsi.NewResolver(cfg.GitLab.URL)
+
+	secrets := []*string{&cfg.GitLab.Token}
+	if cfg.Notifier.SlackWebHook.Enabled {
+		secrets = append(secrets, &cfg.Notifier.SlackWebHook.URL)
+	}
+	if cfg.Notifier.Telegram.Enabled {
+		secrets = append(secrets, &cfg.Notifier.Telegram.APIKey)
+	}
+	if cfg.Notifier.MattermostWebHook.Enabled {
+		secrets = append(secrets, &cfg.Notifier.MattermostWebHook.URL)
+	}
+	if cfg.Jira.Enabled {
+		secrets = append(secrets, &cfg.Jira.Token)
+	}
+
+	for _, s := range secrets {
+		if *s == "" {
+			continue
+		}
+
+		v, err := r.Resolve(ctx, *s)
+		if err != nil {
+			return err
+		}
+
+		*s = v
+	}
+
+	return nil
+}
+
 func applyFlags(flags *pflag.FlagSet, cfg *config.Config) error {
 	t, err := flags.GetString("token")
 	if err != nil {
@@ -125,69 +350,138 @@ func applyFlags(flags *pflag.FlagSet, cfg *config.Config) error {
 	}
 
 	if h != "" {
-		cfg.GitLab.URL = t
+		cfg.GitLab.URL = h
 	}
 	if cfg.GitLab.URL == "" {
 		cfg.GitLab.URL = "https://gitlab.com"
 	}
 
-	mrt, err := flags.GetString("title")
+	r, err := flags.GetString("remote")
 	if err != nil {
 		return err
 	}
 
-	if mrt != "" {
-		cfg.MR.Title = mrt
+	if r != "" {
+		cfg.MR.Remote = r
 	}
 
-	mrd, err := flags.GetString("description")
+	tr, err := flags.GetString("target_remote")
 	if err != nil {
 		return err
 	}
 
-	if mrd != "" {
-		cfg.MR.Description = mrd
+	if tr != "" {
+		cfg.MR.TargetRemote = tr
 	}
 
-	var target string
-	if flags.Changed("target") {
-		target, err = flags.GetString("target")
+	return applyMRFlags(flags, cfg)
+}
+
+// applyMRFlags applies flags of commands working with MR, flags not
+// defined for current command are skipped.
+func applyMRFlags(flags *pflag.FlagSet, cfg *config.Config) error {
+	if flags.Lookup("title") != nil {
+		mrt, err := flags.GetString("title")
 		if err != nil {
 			return err
 		}
+
+		if mrt != "" {
+			cfg.MR.Title = mrt
+		}
 	}
 
-	if target != "" {
-		cfg.MR.TargetBranch = target
+	if flags.Lookup("description") != nil {
+		mrd, err := flags.GetString("description")
+		if err != nil {
+			return err
+		}
+
+		if mrd != "" {
+			cfg.MR.Description = mrd
+		}
+	}
+
+	if flags.Lookup("existing") != nil {
+		e, err := flags.GetString("existing")
+		if err != nil {
+			return err
+		}
+
+		if e != "" {
+			cfg.MR.Existing = e
+		}
+	}
+
+	if flags.Lookup("draft") != nil {
+		d, err := flags.GetBool("draft")
+		if err != nil {
+			return err
+		}
+
+		if d {
+			cfg.MR.Draft = true
+		}
+	}
+
+	if flags.Lookup("push") != nil {
+		p, err := flags.GetBool("push")
+		if err != nil {
+			return err
+		}
+
+		if p {
+			cfg.MR.Push = true
+		}
+	}
+
+	if flags.Changed("target") {
+		target, err := flags.GetString("target")
+		if err != nil {
+			return err
+		}
+
+		if target != "" {
+			cfg.MR.TargetBranch = target
+		}
 	}
 
 	return nil
 }
 
 func createCore(dryRun bool, out io.StringWriter, cfg *config.Config) (*glmt.Core, error) {
-	git, err := git.NewLocalGit()
+	lg, err := git.NewLocalGit(cfg.MR.Remote, cfg.MR.TargetRemote, cfg.GitLab.URL, cfg.GitLab.Token)
 	if err != nil {
 		return nil, err
 	}
 
+	var g glmt.Git = lg
+	if dryRun {
+		g = git.NewDryRunGit(lg, out)
+	}
+
 	gitCfg := cfg.GitLab
 	var gitlab gitlab.GitLab
 	if dryRun {
 		gitlab = gitlabi.NewDryRunGitLab(out, gitCfg.Token, gitCfg.URL)
 	} else {
-		gitlab = gitlabi.NewHTTPGitLab(gitCfg.Token, gitCfg.URL)
+		gitlab = gitlabi.NewHTTPGitLab(gitCfg)
+
+		if cd, err := os.UserCacheDir(); err == nil {
+			gitlab = gitlabi.NewUserCache(gitlab, gitCfg.URL, filepath.Join(cd, "glmt", "users.json"))
+		}
 	}
 
 	nfyCfg := cfg.Notifier
 	var ns []notifier.Notifier
 	if nfyCfg.SlackWebHook.Enabled {
-		ns = append(ns, notifieri.NewSlackWebHookNotifier(nfyCfg.SlackWebHook))
+		ns = append(ns, notifieri.NewSlackWebHookNotifier(nfyCfg.SlackWebHook, cfg.Templating.Lenient))
 	}
 	if nfyCfg.Telegram.Enabled {
-		ns = append(ns, notifieri.NewTelegramNotifier(nfyCfg.Telegram))
+		ns = append(ns, notifieri.NewTelegramNotifier(nfyCfg.Telegram, cfg.Templating.Lenient))
 	}
 	if nfyCfg.MattermostWebHook.Enabled {
-		ns = append(ns, notifieri.NewMattermostWebHookNotifier(nfyCfg.MattermostWebHook))
+		ns = append(ns, notifieri.NewMattermostWebHookNotifier(nfyCfg.MattermostWebHook, cfg.Templating.Lenient))
 	}
 	n := notifieri.NewMultiNotifier(ns...)
 
@@ -200,5 +494,16 @@ func createCore(dryRun bool, out io.StringWriter, cfg *config.Config) (*glmt.Cor
 	hsCfg := cfg.Hooks
 	hs := hooksi.NewHooks(hsCfg, os.Stdout, os.Stderr)
 
-	return glmt.NewGLMT(git, gitlab, n, ts, hs), nil
+	var j jira.Jira
+	if cfg.Jira.Enabled {
+		if dryRun {
+			j = jirai.NewDryRunJira(out, cfg.Jira)
+		} else {
+			j = jirai.NewHTTPJira(cfg.Jira)
+		}
+	}
+
+	ed := editori.NewExternalEditor(cfg.MR.Editor, os.Stdin, os.Stdout, os.Stderr)
+
+	return glmt.NewGLMT(g, gitlab, n, ts, hs, j, ed), nil
 }
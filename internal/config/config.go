@@ -15,17 +15,35 @@ import (
 )
 
 type Config struct {
-	Base      string    `json:"base"`
-	GitLab    GitLab    `json:"gitlab"`
-	MR        MR        `json:"mr"`
-	Notifier  Notifier  `json:"notifier"`
-	Mentioner Mentioner `json:"mentioner"`
-	Hooks     Hooks     `json:"hooks"`
+	Base       string     `json:"base"`
+	GitLab     GitLab     `json:"gitlab"`
+	MR         MR         `json:"mr"`
+	Notifier   Notifier   `json:"notifier"`
+	Mentioner  Mentioner  `json:"mentioner"`
+	Hooks      Hooks      `json:"hooks"`
+	Checks     Checks     `json:"checks"`
+	Jira       Jira       `json:"jira"`
+	Changelog  Changelog  `json:"changelog"`
+	Templating Templating `json:"templating"`
 }
 
 type GitLab struct {
 	URL   string `json:"url"`
 	Token string `json:"token"`
+	// Timeout of a single request attempt, default is 30s.
+	Timeout Duration `json:"timeout"`
+	Retry   Retry    `json:"retry"`
+}
+
+// Retry configures retries of GitLab's requests on network errors,
+// rate limits (429) and unavailable GitLab (502, 503, 504).
+type Retry struct {
+	// MaxAttempts including first one, default is 3, set 1 to disable retries.
+	MaxAttempts int `json:"max_attempts"`
+	// MinBackoff is a delay before first retry, it is doubled for every next retry
+	// up to MaxBackoff. Defaults are 500ms and 10s.
+	MinBackoff Duration `json:"min_backoff"`
+	MaxBackoff Duration `json:"max_backoff"`
 }
 
 type MR struct {
@@ -36,6 +54,49 @@ type MR struct {
 	Squash             bool     `json:"squash"`
 	RemoveSourceBranch bool     `json:"remove_source_branch"`
 	LabelVars          []string `json:"label_vars"`
+	// Labels are rules of MR labels in addition to LabelVars.
+	Labels Labels `json:"labels"`
+	// Existing is a mode of handling already opened MR for the same branches:
+	// "update" (default), "keep" or "fail".
+	Existing string `json:"existing"`
+	// Draft creates MR as draft, notifications are sent by "glmt ready".
+	Draft bool `json:"draft"`
+	// SquashCommitMessage and MergeCommitMessage are templates of commit
+	// messages used by "glmt merge".
+	SquashCommitMessage string `json:"squash_commit_message"`
+	MergeCommitMessage  string `json:"merge_commit_message"`
+	// Push pushes current branch before creating MR, by default MR is
+	// not created if branch has unpushed commits.
+	Push bool `json:"push"`
+	// Issue is a GitLab issue referenced by branch.
+	Issue Issue `json:"issue"`
+	// Remote is a git remote of current branch, by default branch's tracking
+	// remote or "glmt.remote" option of repo's git config is used.
+	Remote string `json:"remote"`
+	// TargetRemote is a git remote of project MRs are created to when working
+	// from fork, "upstream" remote is used by default if present.
+	TargetRemote string `json:"target_remote"`
+	// Templates maps project path to name of its default MR template from
+	// .gitlab/merge_request_templates, "*" is used for any other project.
+	Templates map[string]string `json:"templates"`
+	// Editor is a command editing MR with "glmt create --edit",
+	// default is $VISUAL, $EDITOR or vi.
+	Editor string `json:"editor"`
+	// TargetRules choose target branch by current branch, the first matching
+	// rule is used, TargetBranch is used if none matches.
+	TargetRules []TargetRule `json:"target_rules"`
+	// TargetCandidates are globs of remote branches "auto" target is chosen
+	// from, all remote branches are used if empty.
+	TargetCandidates []string `json:"target_candidates"`
+	// Backport configures MRs created by "glmt backport".
+	Backport Backport `json:"backport"`
+}
+
+// Backport configures backport MRs, Title and Description template variables
+// are title and description of original MR.
+type Backport struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
 }
 
 type Notifier struct {
@@ -61,11 +122,107 @@ type SlackWebHook struct {
 type Mentioner struct {
 	TeamFileSource string `json:"team_file_source"`
 	MentionsCount  int    `json:"count"`
+	// Mode tells how to use selected members: "mention" (default) only mentions
+	// them, "reviewers", "assignees" or "both" also set them in MR.
+	Mode string `json:"mode"`
+}
+
+// Issue configures GitLab issue referenced by branch.
+type Issue struct {
+	// Var is a name of branch_regexp group with issue number.
+	Var           string `json:"var"`
+	Close         bool   `json:"close"`
+	CopyLabels    bool   `json:"copy_labels"`
+	CopyMilestone bool   `json:"copy_milestone"`
+}
+
+// Labels configures MR labels.
+type Labels struct {
+	// Static labels are added to every MR.
+	Static []string `json:"static"`
+	// Map maps values of branch_regexp groups to labels, like
+	// {"TaskType": {"feat": "type::feature"}}.
+	Map map[string]map[string]string `json:"map"`
+	// BranchRules add labels if branch matches regexp.
+	BranchRules []LabelRule `json:"branch_rules"`
+	// Validate checks labels against project's labels and warns about unknown ones.
+	Validate bool `json:"validate"`
+	// Strict refuses to create MR with unknown labels, implies Validate.
+	Strict bool `json:"strict"`
+}
+
+type LabelRule struct {
+	Regexp string   `json:"regexp"`
+	Labels []string `json:"labels"`
+}
+
+// TargetRule maps branch to target branch. Branch is a glob of current branch,
+// Var and Value are a name of branch_regexp group and a glob of its value.
+// Target is a branch name, "auto" or a glob choosing the newest remote branch.
+type TargetRule struct {
+	Branch string `json:"branch"`
+	Var    string `json:"var"`
+	Value  string `json:"value"`
+	Target string `json:"target"`
+}
+
+// Templating configures rendering of templates.
+type Templating struct {
+	// Lenient ignores template errors and renders unknown variables empty,
+	// by default errors are reported and templates are validated at startup.
+	Lenient bool `json:"lenient"`
+}
+
+// Changelog configures "glmt changelog".
+type Changelog struct {
+	// Template renders changelog, default is markdown list of MRs by groups.
+	Template string `json:"template"`
+	// TargetBranch limits MRs to those merged into branch.
+	TargetBranch string `json:"target_branch"`
+	// GroupVar is a name of branch_regexp group MRs are grouped by.
+	GroupVar string `json:"group_var"`
+	// Groups are matched in order, without groups MRs are grouped by values of GroupVar.
+	Groups []ChangelogGroup `json:"groups"`
+	// OtherGroup is a title of MRs matching no group, default is "Other".
+	OtherGroup string `json:"other_group"`
+}
+
+// ChangelogGroup matches MRs with any of Labels or with value of GroupVar from Values.
+type ChangelogGroup struct {
+	Title  string   `json:"title"`
+	Labels []string `json:"labels"`
+	Values []string `json:"values"`
+}
+
+// Jira configures Jira issue referenced by branch.
+type Jira struct {
+	Enabled bool   `json:"enabled"`
+	URL     string `json:"url"`
+	// Username is set for Jira Cloud with API token, Jira Server's personal
+	// access token is used without username.
+	Username string   `json:"username"`
+	Token    string   `json:"token"`
+	Timeout  Duration `json:"timeout"`
+	// Var is a name of branch_regexp group with issue key, like PROJ-123.
+	Var string `json:"var"`
+	// TransitionTo is a status issue is moved to after MR creation, empty disables transition.
+	TransitionTo string `json:"transition_to"`
+}
+
+// Checks of local repository made before MR creation.
+type Checks struct {
+	CleanWorktree bool `json:"clean_worktree"`
+	UpToDate      bool `json:"up_to_date"`
+	BehindTarget  bool `json:"behind_target"`
+	// MaxBehindTarget is max number of target branch's commits missing in branch.
+	MaxBehindTarget int  `json:"max_behind_target"`
+	NoFixupCommits  bool `json:"no_fixup_commits"`
 }
 
 type Hooks struct {
 	AfterCommands  map[string][]string `json:"after"`
 	BeforeCommands map[string][]string `json:"before"`
+	MergeCommands  map[string][]string `json:"merge"`
 	Timeout        Duration            `json:"timeout"`
 }
 
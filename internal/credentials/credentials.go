@@ -0,0 +1,9 @@
+// Package credentials defines sources of secrets referenced in config
+package credentials
+
+import "context"
+
+// Source returns secrets by key, key format depends on source.
+type Source interface {
+	Secret(ctx context.Context, key string) (string, error)
+}
@@ -0,0 +1,44 @@
+package impl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const commandTimeout = 30 * time.Second
+
+// CommandSource runs helper command (like "pass show gitlab/token") and returns its
+// output as secret, key is command with space separated arguments. Command is
+// run without shell.
+type CommandSource struct{}
+
+func (CommandSource) Secret(ctx context.Context, key string) (string, error) {
+	args := strings.Fields(key)
+	if len(args) == 0 {
+		return "", errors.New("empty command")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("running %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	s := strings.TrimRight(string(out), "\r\n")
+	if s == "" {
+		return "", errors.New("command " + args[0] + " returned empty secret")
+	}
+
+	return s, nil
+}
@@ -0,0 +1,19 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+// EnvSource reads secrets from environment variables, key is variable's name.
+type EnvSource struct{}
+
+func (EnvSource) Secret(ctx context.Context, key string) (string, error) {
+	s, ok := os.LookupEnv(key)
+	if !ok || s == "" {
+		return "", errors.New("environment variable " + key + " is not set")
+	}
+
+	return s, nil
+}
@@ -0,0 +1,39 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileSource reads secrets from files, key is file's path ("~/" is expanded
+// to home directory). Trailing new lines are trimmed.
+type FileSource struct{}
+
+func (FileSource) Secret(ctx context.Context, key string) (string, error) {
+	path := key
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("can not find home directory: %w", err)
+		}
+
+		path = filepath.Join(home, path[2:])
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("can not read secret file: %w", err)
+	}
+
+	s := strings.TrimRight(string(data), "\r\n")
+	if s == "" {
+		return "", errors.New("secret file " + path + " is empty")
+	}
+
+	return s, nil
+}
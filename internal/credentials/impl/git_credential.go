@@ -0,0 +1,55 @@
+package impl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GitCredentialSource asks git's credential helpers for password
+// of URL with "git credential fill", key is URL.
+type GitCredentialSource struct{}
+
+func (GitCredentialSource) Secret(ctx context.Context, key string) (string, error) {
+	u, err := url.Parse(key)
+	if err != nil || u.Host == "" {
+		return "", errors.New("invalid url for git credential: " + key)
+	}
+
+	var in bytes.Buffer
+	_, _ = fmt.Fprintf(&in, "protocol=%s\nhost=%s\n", u.Scheme, u.Host)
+	if u.User != nil && u.User.Username() != "" {
+		_, _ = fmt.Fprintf(&in, "username=%s\n", u.User.Username())
+	}
+	_, _ = in.WriteString("\n")
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Stdin = &in
+	cmd.Stderr = &stderr
+	// never ask user in terminal, glmt output could be piped
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("running git credential fill: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		if p := strings.TrimPrefix(sc.Text(), "password="); p != sc.Text() && p != "" {
+			return p, nil
+		}
+	}
+
+	return "", errors.New("git credential helper returned no password for " + u.Host)
+}
@@ -0,0 +1,81 @@
+// Package impl implements credentials.Source
+package impl
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/credentials"
+)
+
+// Prefixes of secret references in config.
+const (
+	prefixEnv           = "env:"
+	prefixFile          = "file:"
+	prefixCommand       = "cmd:"
+	prefixGitCredential = "git-credential"
+)
+
+// NewResolver creates resolver, gitLabURL is used for "git-credential"
+// references without URL.
+func NewResolver(gitLabURL string) *Resolver {
+	return &Resolver{
+		gitLabURL: gitLabURL,
+		sources: map[string]credentials.Source{
+			prefixEnv:           EnvSource{},
+			prefixFile:          FileSource{},
+			prefixCommand:       CommandSource{},
+			prefixGitCredential: GitCredentialSource{},
+		},
+	}
+}
+
+// Resolver resolves secret references:
+//
+//	env:NAME - environment variable NAME
+//	file:PATH - content of file PATH
+//	cmd:COMMAND ARGS - output of COMMAND
+//	git-credential[:URL] - password from "git credential fill" for URL (GitLab's URL by default)
+//
+// Any other value is a secret itself.
+type Resolver struct {
+	gitLabURL string
+	sources   map[string]credentials.Source
+}
+
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	prefix, key := splitReference(value)
+	if prefix == "" {
+		return value, nil
+	}
+
+	if prefix == prefixGitCredential && key == "" {
+		key = r.gitLabURL
+	}
+
+	s, err := r.sources[prefix].Secret(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("can not resolve secret %q: %w", value, err)
+	}
+
+	return s, nil
+}
+
+func splitReference(value string) (prefix, key string) {
+	for _, p := range []string{prefixEnv, prefixFile, prefixCommand} {
+		if strings.HasPrefix(value, p) {
+			return p, strings.TrimPrefix(value, p)
+		}
+	}
+
+	if value == prefixGitCredential {
+		return prefixGitCredential, ""
+	}
+
+	if strings.HasPrefix(value, prefixGitCredential+":") {
+		return prefixGitCredential, strings.TrimPrefix(value, prefixGitCredential+":")
+	}
+
+	return "", value
+}
@@ -0,0 +1,62 @@
+package impl_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/credentials/impl"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	const secret = "glpat-secret"
+
+	os.Setenv("GLMT_TEST_TOKEN", secret)
+	defer os.Unsetenv("GLMT_TEST_TOKEN")
+
+	path := filepath.Join(t.TempDir(), "token")
+	err := ioutil.WriteFile(path, []byte(secret+"\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs := map[string]string{
+		secret:                    secret,
+		"https://hooks.x/y":       "https://hooks.x/y",
+		"123456:telegram-key":     "123456:telegram-key",
+		"env:GLMT_TEST_TOKEN":     secret,
+		"file:" + path:            secret,
+		"cmd:echo " + secret:      secret,
+		"cmd:printf %s " + secret: secret,
+	}
+
+	r := impl.NewResolver("https://gitlab.com")
+	for ref, exp := range refs {
+		got, err := r.Resolve(context.Background(), ref)
+		if err != nil {
+			t.Fatalf("%s: %v", ref, err)
+		}
+
+		if got != exp {
+			t.Fatalf("%s: exp: %q, got: %q", ref, exp, got)
+		}
+	}
+}
+
+func TestResolver_ResolveErrors(t *testing.T) {
+	refs := []string{
+		"env:GLMT_TEST_NOT_EXISTING_VARIABLE",
+		"file:/not/existing/file",
+		"cmd:this-command-should-not-exists",
+	}
+
+	r := impl.NewResolver("https://gitlab.com")
+	for _, ref := range refs {
+		_, err := r.Resolve(context.Background(), ref)
+		if err == nil {
+			t.Fatalf("%s: expected error", ref)
+		}
+	}
+}
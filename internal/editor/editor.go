@@ -0,0 +1,9 @@
+// Package editor defines interface for editing text by user
+package editor
+
+import "context"
+
+type Editor interface {
+	// Edit opens text in editor and returns text saved by user.
+	Edit(ctx context.Context, text string) (string, error)
+}
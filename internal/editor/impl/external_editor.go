@@ -0,0 +1,87 @@
+// Package impl implements editor.Editor.
+package impl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const defaultEditor = "vi"
+
+// ExternalEditor edits text in temporary file with editor command.
+type ExternalEditor struct {
+	command []string
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// NewExternalEditor creates editor running command with file name as last argument.
+// If command is empty $VISUAL, $EDITOR or vi is used.
+func NewExternalEditor(command string, stdin io.Reader, stdout, stderr io.Writer) *ExternalEditor {
+	for _, c := range []string{command, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if strings.TrimSpace(c) != "" {
+			command = c
+			break
+		}
+	}
+
+	if strings.TrimSpace(command) == "" {
+		command = defaultEditor
+	}
+
+	return &ExternalEditor{
+		command: strings.Fields(command),
+		stdin:   stdin,
+		stdout:  stdout,
+		stderr:  stderr,
+	}
+}
+
+func (e *ExternalEditor) Edit(ctx context.Context, text string) (string, error) {
+	f, err := ioutil.TempFile("", "glmt-*.md")
+	if err != nil {
+		return "", fmt.Errorf("can not create file to edit: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(text)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("can not write file to edit: %w", err)
+	}
+
+	args := make([]string, 0, len(e.command))
+	args = append(args, e.command[1:]...)
+	args = append(args, f.Name())
+
+	cmd := exec.CommandContext(ctx, e.command[0], args...)
+	cmd.Stdin = e.stdin
+	cmd.Stdout = e.stdout
+	cmd.Stderr = e.stderr
+
+	err = cmd.Run()
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		return "", fmt.Errorf("editor %s exited with code %d", e.command[0], ee.ExitCode())
+	}
+	if err != nil {
+		return "", fmt.Errorf("can not run editor %s: %w", e.command[0], err)
+	}
+
+	edited, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("can not read edited file: %w", err)
+	}
+
+	return string(edited), nil
+}
@@ -0,0 +1,26 @@
+package impl
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExternalEditor_Edit(t *testing.T) {
+	// sed edits file in place like interactive editor
+	e := NewExternalEditor("sed -i -e s/draft/ready/", nil, nil, nil)
+
+	text, err := e.Edit(context.Background(), "draft text\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if text != "ready text\n" {
+		t.Fatalf("unexpected edited text: %q", text)
+	}
+
+	e = NewExternalEditor("false", nil, nil, nil)
+	_, err = e.Edit(context.Background(), "text")
+	if err == nil {
+		t.Fatal("expected error of failed editor")
+	}
+}
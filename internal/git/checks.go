@@ -0,0 +1,112 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// DirtyFiles returns modified and staged files of worktree,
+// untracked files are not reported.
+func (lg *LocalGit) DirtyFiles() ([]string, error) {
+	wt, err := lg.repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("can not open worktree: %w", err)
+	}
+
+	st, err := wt.Status()
+	if err != nil {
+		return nil, fmt.Errorf("can not get worktree status: %w", err)
+	}
+
+	var files []string
+	for f, s := range st {
+		if s.Worktree == git.Untracked || s.Worktree == git.Unmodified && s.Staging == git.Unmodified {
+			continue
+		}
+
+		files = append(files, f)
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// InSync reports if branch points to the same commit as its upstream branch on remote.
+// Branch without configured upstream is compared with remote's branch of the same name.
+func (lg *LocalGit) InSync(remote, branch string) (bool, error) {
+	local, err := lg.repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return false, fmt.Errorf("can not find branch %s: %w", branch, err)
+	}
+
+	name, err := lg.trackedBranch(remote, branch)
+	if err != nil {
+		return false, err
+	}
+	if name == "" {
+		name = branch
+	}
+
+	rr, err := lg.repo.Reference(plumbing.NewRemoteReferenceName(remote, name), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("can not find remote branch %s/%s: %w", remote, name, err)
+	}
+
+	return local.Hash() == rr.Hash(), nil
+}
+
+// Behind returns number of target branch's commits missing in branch. Target branch
+// is taken from target remote or, if it was not fetched, from local branches.
+func (lg *LocalGit) Behind(branch, target string) (int, error) {
+	bc, tc, err := lg.branchAndTarget(branch, target)
+	if err != nil {
+		return 0, err
+	}
+
+	cs, err := missingCommits(tc, bc)
+	if err != nil {
+		return 0, fmt.Errorf("can not compare %s with %s: %w", branch, target, err)
+	}
+
+	return len(cs), nil
+}
+
+// Divergence returns numbers of branch's commits missing in target branch and
+// of target branch's commits missing in branch. Merge base is computed once,
+// so both numbers are counted without walking the whole history twice.
+func (lg *LocalGit) Divergence(branch, target string) (int, int, error) {
+	bc, tc, err := lg.branchAndTarget(branch, target)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	bases, err := bc.MergeBase(tc)
+	if err != nil {
+		return 0, 0, fmt.Errorf("can not find merge base of %s and %s: %w", branch, target, err)
+	}
+
+	excluded, err := ancestors(bases...)
+	if err != nil {
+		return 0, 0, fmt.Errorf("can not compare %s with %s: %w", branch, target, err)
+	}
+
+	ahead, err := countCommits(bc, excluded)
+	if err != nil {
+		return 0, 0, fmt.Errorf("can not compare %s with %s: %w", branch, target, err)
+	}
+
+	behind, err := countCommits(tc, excluded)
+	if err != nil {
+		return 0, 0, fmt.Errorf("can not compare %s with %s: %w", branch, target, err)
+	}
+
+	return ahead, behind, nil
+}
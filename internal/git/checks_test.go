@@ -0,0 +1,151 @@
+package git
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestLocalGit_Checks(t *testing.T) {
+	dir := t.TempDir()
+
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit := func(file, content, msg string) plumbing.Hash {
+		err := ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = wt.Add(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		h, err := wt.Commit(msg, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return h
+	}
+
+	base := commit("a.txt", "a", "initial")
+	commit("b.txt", "b", "master change")
+
+	err = wt.Checkout(&git.CheckoutOptions{
+		Hash:   base,
+		Branch: plumbing.NewBranchReferenceName("feature"),
+		Create: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit("c.txt", "c", "fixup! initial")
+
+	lg := &LocalGit{repo: r}
+
+	n, err := lg.Behind("feature", "master")
+	if err != nil || n != 1 {
+		t.Fatal("expected feature behind master by 1 commit", n, err)
+	}
+
+	cs, err := lg.BranchCommits("feature", "master")
+	if err != nil || len(cs) != 1 || cs[0].Subject != "fixup! initial" || cs[0].Author != "test" {
+		t.Fatal("wrong branch commits", cs, err)
+	}
+
+	ahead, behind, err := lg.Divergence("feature", "master")
+	if err != nil || ahead != 1 || behind != 1 {
+		t.Fatal("expected feature 1 commit ahead and 1 behind master", ahead, behind, err)
+	}
+
+	ds, err := lg.DiffStat("feature", "master")
+	if err != nil || ds != (DiffStat{FilesChanged: 1, Insertions: 1}) {
+		t.Fatal("wrong diff stat", ds, err)
+	}
+
+	fr, err := r.Reference(plumbing.NewBranchReferenceName("feature"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", "my-feature"), fr.Hash()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := lg.InSync("origin", "feature")
+	if err != nil || ok {
+		t.Fatal("expected feature without upstream out of sync", err)
+	}
+
+	cfg, err := r.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Branches["feature"] = &config.Branch{Name: "feature", Remote: "origin", Merge: "refs/heads/my-feature"}
+	err = r.SetConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err = lg.InSync("origin", "feature")
+	if err != nil || !ok {
+		t.Fatal("expected feature in sync with its upstream", err)
+	}
+
+	files, err := lg.DirtyFiles()
+	if err != nil || len(files) != 0 {
+		t.Fatal("expected clean worktree", files, err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "a.txt"), []byte("changed"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, "untracked.txt"), []byte("new"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err = lg.DirtyFiles()
+	if err != nil || !reflect.DeepEqual(files, []string{"a.txt"}) {
+		t.Fatal("wrong dirty files", files, err)
+	}
+
+	names, err := lg.ReadDir(".")
+	if err != nil || !reflect.DeepEqual(names, []string{"a.txt", "c.txt", "untracked.txt"}) {
+		t.Fatal("wrong directory files", names, err)
+	}
+
+	names, err = lg.ReadDir("missing")
+	if err != nil || len(names) != 0 {
+		t.Fatal("expected no files in missing directory", names, err)
+	}
+
+	content, err := lg.ReadFile("a.txt")
+	if err != nil || string(content) != "changed" {
+		t.Fatal("wrong file content", string(content), err)
+	}
+}
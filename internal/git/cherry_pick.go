@@ -0,0 +1,159 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/rs/zerolog/log"
+)
+
+// CherryPick creates branch from target remote's base branch and cherry-picks
+// commits onto it with references to original commits (cherry-pick -x). go-git
+// can not cherry-pick, so git command is used in temporary worktree and current
+// worktree is left untouched. Existing branch is reused as is, so interrupted
+// backport can be continued. Merge commits are skipped.
+func (lg *LocalGit) CherryPick(ctx context.Context, branch, base string, commits []string) error {
+	_, err := lg.repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err == nil {
+		log.Ctx(ctx).Info().Str("branch", branch).Msg("branch already exists, commits are not cherry-picked")
+		return nil
+	}
+
+	commits, err = lg.skipMerges(ctx, commits)
+	if err != nil {
+		return err
+	}
+
+	if len(commits) == 0 {
+		return errors.New("no commits to cherry-pick")
+	}
+
+	remote, err := lg.targetRemoteName()
+	if err != nil {
+		return err
+	}
+
+	root, err := lg.root()
+	if err != nil {
+		return err
+	}
+
+	_, err = runGit(ctx, root, "fetch", remote, base)
+	if err != nil {
+		return fmt.Errorf("can not fetch %s: %w", base, err)
+	}
+
+	dir, err := ioutil.TempDir("", "glmt-cherry-pick-")
+	if err != nil {
+		return fmt.Errorf("can not create worktree dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, err = runGit(ctx, root, "worktree", "add", "-b", branch, dir, "FETCH_HEAD")
+	if err != nil {
+		return fmt.Errorf("can not create branch %s: %w", branch, err)
+	}
+
+	_, pickErr := runGit(ctx, dir, append([]string{"cherry-pick", "-x"}, commits...)...)
+
+	var conflicts string
+	if pickErr != nil {
+		conflicts, _ = runGit(ctx, dir, "diff", "--name-only", "--diff-filter=U")
+		_, _ = runGit(ctx, dir, "cherry-pick", "--abort")
+	}
+
+	_, err = runGit(ctx, root, "worktree", "remove", "--force", dir)
+	if err != nil {
+		return fmt.Errorf("can not remove worktree: %w", err)
+	}
+
+	if pickErr == nil {
+		return nil
+	}
+
+	// branch of failed cherry-pick is useless
+	_, _ = runGit(ctx, root, "branch", "-D", branch)
+
+	if conflicts != "" {
+		return fmt.Errorf("%w in %s", ErrConflict, strings.Join(strings.Fields(conflicts), ", "))
+	}
+
+	return fmt.Errorf("can not cherry-pick: %w", pickErr)
+}
+
+// skipMerges removes merge commits, they bring changes of merged branch
+// rather than changes of MR.
+func (lg *LocalGit) skipMerges(ctx context.Context, commits []string) ([]string, error) {
+	r := make([]string, 0, len(commits))
+	for _, sha := range commits {
+		c, err := lg.repo.CommitObject(plumbing.NewHash(sha))
+		if err != nil {
+			return nil, fmt.Errorf("can not read commit %s: %w", sha, err)
+		}
+
+		if c.NumParents() > 1 {
+			log.Ctx(ctx).Info().Str("commit", sha).Msg("merge commit is skipped")
+			continue
+		}
+
+		r = append(r, sha)
+	}
+
+	return r, nil
+}
+
+// FetchMR fetches head of target remote's MR, GitLab keeps it in
+// refs/merge-requests/<iid>/head even after source branch is removed.
+func (lg *LocalGit) FetchMR(ctx context.Context, iid int64) error {
+	remote, err := lg.targetRemoteName()
+	if err != nil {
+		return err
+	}
+
+	root, err := lg.root()
+	if err != nil {
+		return err
+	}
+
+	_, err = runGit(ctx, root, "fetch", remote, "refs/merge-requests/"+strconv.FormatInt(iid, 10)+"/head")
+	if err != nil {
+		return fmt.Errorf("can not fetch MR !%d: %w", iid, err)
+	}
+
+	return nil
+}
+
+// root returns directory of repository's worktree.
+func (lg *LocalGit) root() (string, error) {
+	wt, err := lg.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("can not open worktree: %w", err)
+	}
+
+	return wt.Filesystem.Root(), nil
+}
+
+// runGit runs git command in dir.
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return stdout.String(), fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
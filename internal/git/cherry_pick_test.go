@@ -0,0 +1,149 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestLocalGit_CherryPick(t *testing.T) {
+	dir := t.TempDir()
+
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// git command commits cherry-picks
+	cfg, err := r.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Raw.Section("user").SetOption("name", "test")
+	cfg.Raw.Section("user").SetOption("email", "test@example.com")
+
+	err = r.SetConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// repository is its own remote
+	_, err = r.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit := func(file, content string) plumbing.Hash {
+		err := ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = wt.Add(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		h, err := wt.Commit(file+" "+content, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return h
+	}
+
+	checkout := func(branch string, create bool) {
+		err := wt.Checkout(&git.CheckoutOptions{
+			Branch: plumbing.NewBranchReferenceName(branch),
+			Create: create,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commit("a", "base")
+	checkout("release", true)
+	commit("b", "release")
+	checkout("master", false)
+	checkout("feature", true)
+	fix := commit("a", "fix")
+	conflicting := commit("b", "feature")
+
+	lg := &LocalGit{repo: r, remote: "origin"}
+	ctx := context.Background()
+
+	err = lg.CherryPick(ctx, "backport/release/fix", "release", []string{fix.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := r.Reference(plumbing.NewBranchReferenceName("backport/release/fix"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := r.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.NumParents() != 1 || !strings.Contains(c.Message, "cherry picked from commit "+fix.String()) {
+		t.Fatal("wrong cherry-picked commit", c)
+	}
+
+	// rerun reuses branch
+	err = lg.CherryPick(ctx, "backport/release/fix", "release", []string{fix.String()})
+	if err != nil {
+		t.Fatal("rerun failed", err)
+	}
+
+	merge, err := r.CommitObject(fix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merge.ParentHashes = append(merge.ParentHashes, conflicting)
+	obj := r.Storer.NewEncodedObject()
+	err = merge.Encode(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mh, err := r.Storer.SetEncodedObject(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = lg.CherryPick(ctx, "backport/release/merge", "release", []string{fix.String(), mh.String()})
+	if err != nil {
+		t.Fatal("merge commit should be skipped", err)
+	}
+
+	err = lg.CherryPick(ctx, "backport/release/conflict", "release", []string{conflicting.String()})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatal("exp conflict, got", err)
+	}
+
+	_, err = r.Reference(plumbing.NewBranchReferenceName("backport/release/conflict"), true)
+	if err == nil {
+		t.Fatal("branch of conflicting cherry-pick should be removed")
+	}
+}
@@ -0,0 +1,218 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const shortSHALen = 8
+
+// BranchCommits returns branch's commits missing in target branch, newest
+// commits go first.
+func (lg *LocalGit) BranchCommits(branch, target string) ([]Commit, error) {
+	bc, tc, err := lg.branchAndTarget(branch, target)
+	if err != nil {
+		return nil, err
+	}
+
+	cs, err := missingCommits(bc, tc)
+	if err != nil {
+		return nil, fmt.Errorf("can not compare %s with %s: %w", branch, target, err)
+	}
+
+	return toCommits(cs), nil
+}
+
+// RangeCommits returns commits reachable from revision to, but not reachable
+// from revision from, newest commits go first.
+func (lg *LocalGit) RangeCommits(from, to string) ([]Commit, error) {
+	tc, err := lg.revisionCommit(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var fc *object.Commit
+	if from != "" {
+		fc, err = lg.revisionCommit(from)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cs, err := missingCommits(tc, fc)
+	if err != nil {
+		return nil, fmt.Errorf("can not list commits from %s to %s: %w", from, to, err)
+	}
+
+	return toCommits(cs), nil
+}
+
+func (lg *LocalGit) revisionCommit(rev string) (*object.Commit, error) {
+	h, err := lg.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("can not find revision %s: %w", rev, err)
+	}
+
+	c, err := lg.repo.CommitObject(*h)
+	if err != nil {
+		return nil, fmt.Errorf("can not read commit %s: %w", h, err)
+	}
+
+	return c, nil
+}
+
+func toCommits(cs []*object.Commit) []Commit {
+	r := make([]Commit, 0, len(cs))
+	for _, c := range cs {
+		subject, body := splitMessage(c.Message)
+		sha := c.Hash.String()
+
+		r = append(r, Commit{
+			SHA:         sha,
+			ShortSHA:    sha[:shortSHALen],
+			Subject:     subject,
+			Body:        body,
+			Author:      c.Author.Name,
+			AuthorEmail: c.Author.Email,
+			Date:        c.Committer.When,
+		})
+	}
+
+	return r
+}
+
+// DiffStat returns statistics of changes made in branch since merge base with target.
+func (lg *LocalGit) DiffStat(branch, target string) (DiffStat, error) {
+	var ds DiffStat
+
+	bc, tc, err := lg.branchAndTarget(branch, target)
+	if err != nil {
+		return ds, err
+	}
+
+	bases, err := bc.MergeBase(tc)
+	if err != nil {
+		return ds, fmt.Errorf("can not find merge base of %s and %s: %w", branch, target, err)
+	}
+
+	if len(bases) == 0 {
+		return ds, fmt.Errorf("%s and %s have no common commits", branch, target)
+	}
+
+	p, err := bases[0].Patch(bc)
+	if err != nil {
+		return ds, fmt.Errorf("can not diff %s with %s: %w", branch, target, err)
+	}
+
+	for _, fs := range p.Stats() {
+		ds.FilesChanged++
+		ds.Insertions += fs.Addition
+		ds.Deletions += fs.Deletion
+	}
+
+	return ds, nil
+}
+
+func (lg *LocalGit) branchAndTarget(branch, target string) (*object.Commit, *object.Commit, error) {
+	br, err := lg.repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can not find branch %s: %w", branch, err)
+	}
+
+	tr, err := lg.targetRef(target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bc, err := lg.repo.CommitObject(br.Hash())
+	if err != nil {
+		return nil, nil, fmt.Errorf("can not read commit %s: %w", br.Hash(), err)
+	}
+
+	tc, err := lg.repo.CommitObject(tr.Hash())
+	if err != nil {
+		return nil, nil, fmt.Errorf("can not read commit %s: %w", tr.Hash(), err)
+	}
+
+	return bc, tc, nil
+}
+
+func (lg *LocalGit) targetRef(target string) (*plumbing.Reference, error) {
+	// without remote local branch is used
+	if name, err := lg.targetRemoteName(); err == nil {
+		ref, err := lg.repo.Reference(plumbing.NewRemoteReferenceName(name, target), true)
+		if err == nil {
+			return ref, nil
+		}
+	}
+
+	ref, err := lg.repo.Reference(plumbing.NewBranchReferenceName(target), true)
+	if err != nil {
+		return nil, fmt.Errorf("can not find target branch %s: %w", target, err)
+	}
+
+	return ref, nil
+}
+
+// missingCommits returns commits reachable from c, but not reachable from exclude,
+// nil exclude excludes nothing.
+func missingCommits(c, exclude *object.Commit) ([]*object.Commit, error) {
+	excluded := map[plumbing.Hash]bool{}
+	if exclude != nil {
+		var err error
+		excluded, err = ancestors(exclude)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var cs []*object.Commit
+	err := object.NewCommitPreorderIter(c, excluded, nil).ForEach(func(c *object.Commit) error {
+		cs = append(cs, c)
+		return nil
+	})
+
+	return cs, err
+}
+
+// ancestors returns hashes of commits reachable from any of cs.
+func ancestors(cs ...*object.Commit) (map[plumbing.Hash]bool, error) {
+	seen := map[plumbing.Hash]bool{}
+	for _, c := range cs {
+		err := object.NewCommitPreorderIter(c, seen, nil).ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return seen, nil
+}
+
+// countCommits returns number of commits reachable from c, but not in excluded.
+func countCommits(c *object.Commit, excluded map[plumbing.Hash]bool) (int, error) {
+	n := 0
+	err := object.NewCommitPreorderIter(c, excluded, nil).ForEach(func(*object.Commit) error {
+		n++
+		return nil
+	})
+
+	return n, err
+}
+
+// splitMessage returns first line of commit message and the rest of it.
+func splitMessage(msg string) (string, string) {
+	msg = strings.TrimSpace(msg)
+
+	i := strings.IndexByte(msg, '\n')
+	if i < 0 {
+		return msg, ""
+	}
+
+	return strings.TrimSpace(msg[:i]), strings.TrimSpace(msg[i+1:])
+}
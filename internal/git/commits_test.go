@@ -0,0 +1,58 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestLocalGit_RangeCommits(t *testing.T) {
+	r, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit := func(msg string) plumbing.Hash {
+		h, err := wt.Commit(msg, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return h
+	}
+
+	first := commit("first")
+	_, err = r.CreateTag("v1.0.0", first, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit("second")
+	commit("third")
+
+	lg := &LocalGit{repo: r}
+
+	cs, err := lg.RangeCommits("v1.0.0", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cs) != 2 || cs[0].Subject != "third" || cs[1].Subject != "second" || cs[0].Date.IsZero() {
+		t.Fatal("wrong commits", cs)
+	}
+
+	cs, err = lg.RangeCommits("", "HEAD")
+	if err != nil || len(cs) != 3 {
+		t.Fatal("all commits expected", cs, err)
+	}
+}
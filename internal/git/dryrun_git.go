@@ -0,0 +1,31 @@
+package git
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// NewDryRunGit returns git that reads local repository, but only
+// prints pushes instead of doing them.
+func NewDryRunGit(lg *LocalGit, out io.StringWriter) *DryRunGit {
+	return &DryRunGit{
+		LocalGit: lg,
+		out:      out,
+	}
+}
+
+type DryRunGit struct {
+	*LocalGit
+	out io.StringWriter
+}
+
+func (dg *DryRunGit) Push(ctx context.Context, remote, branch string) error {
+	_, _ = dg.out.WriteString("Pushing " + branch + " to " + remote + " and setting upstream\n")
+	return nil
+}
+
+func (dg *DryRunGit) CherryPick(ctx context.Context, branch, base string, commits []string) error {
+	_, _ = dg.out.WriteString("Cherry-picking " + strings.Join(commits, ", ") + " onto " + base + " as " + branch + "\n")
+	return nil
+}
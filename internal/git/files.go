@@ -0,0 +1,57 @@
+package git
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+// ReadDir returns names of files in worktree's directory dir,
+// missing directory has no files.
+func (lg *LocalGit) ReadDir(dir string) ([]string, error) {
+	wt, err := lg.repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("can not open worktree: %w", err)
+	}
+
+	fis, err := wt.Filesystem.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can not read directory %s: %w", dir, err)
+	}
+
+	var names []string
+	for _, fi := range fis {
+		if !fi.IsDir() {
+			names = append(names, fi.Name())
+		}
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// ReadFile returns content of worktree's file.
+func (lg *LocalGit) ReadFile(path string) ([]byte, error) {
+	wt, err := lg.repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("can not open worktree: %w", err)
+	}
+
+	f, err := wt.Filesystem.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can not open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("can not read %s: %w", path, err)
+	}
+
+	return content, nil
+}
@@ -1,2 +1,28 @@
 // Package git implements glmt.Git
 package git
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrConflict is returned by CherryPick if commits conflict with base branch.
+var ErrConflict = errors.New("cherry-pick conflict")
+
+type Commit struct {
+	SHA      string
+	ShortSHA string
+	Subject  string
+	// Body is commit message without subject.
+	Body        string
+	Author      string
+	AuthorEmail string
+	// Date is a date of commit, not of authoring.
+	Date time.Time
+}
+
+type DiffStat struct {
+	FilesChanged int
+	Insertions   int
+	Deletions    int
+}
@@ -1,35 +1,177 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"sort"
+	"strings"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 )
 
-func NewLocalGit() (*LocalGit, error) {
+var ErrAmbiguousRemote = errors.New("can not choose git remote")
+
+const (
+	originRemote   = "origin"
+	upstreamRemote = "upstream"
+	// remoteSection and remoteOption define per-repo git config option
+	// with name of remote, like "git config glmt.remote myremote".
+	remoteSection = "glmt"
+	remoteOption  = "remote"
+)
+
+// NewLocalGit opens repository in current directory. remote is a name of remote
+// with current branch, if empty it is resolved by Remote. targetRemote is a name
+// of remote MRs are created to, if empty "upstream" remote is used when present.
+// token is GitLab token used to push to HTTPS remotes on gitLabURL's host.
+func NewLocalGit(remote, targetRemote, gitLabURL, token string) (*LocalGit, error) {
 	r, err := git.PlainOpen(".")
 	if err != nil {
 		return nil, fmt.Errorf("can not open local git: %w", err)
 	}
 
+	var gitLabHost string
+	if u, err := url.Parse(gitLabURL); err == nil {
+		gitLabHost = u.Hostname()
+	}
+
 	return &LocalGit{
-		repo: r,
+		repo:         r,
+		remote:       remote,
+		targetRemote: targetRemote,
+		gitLabHost:   gitLabHost,
+		token:        token,
 	}, nil
 }
 
 type LocalGit struct {
-	repo *git.Repository
+	repo         *git.Repository
+	remote       string
+	targetRemote string
+	// gitLabHost is a host token is sent to, remotes on other hosts
+	// use git's credential helpers.
+	gitLabHost string
+	token      string
+}
+
+// Remote returns name and URL of remote with current branch. Remote is chosen
+// in order: configured remote, branch's tracking remote, "glmt.remote" option
+// of repo's git config, the only project among remotes (target remote is not
+// considered if there are others).
+func (lg *LocalGit) Remote() (string, string, error) {
+	name, err := lg.remoteName()
+	if err != nil {
+		return "", "", err
+	}
+
+	u, err := lg.remoteURL(name)
+	return name, u, err
 }
 
-func (lg *LocalGit) Remote() (string, error) {
-	r, err := lg.repo.Remote("origin")
+func (lg *LocalGit) remoteName() (string, error) {
+	if lg.remote != "" {
+		return lg.remote, nil
+	}
+
+	cfg, err := lg.repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("can not read git config: %w", err)
+	}
+
+	br, err := lg.CurrentBranch()
 	if err != nil {
-		return "", fmt.Errorf("can not find remote: %w", err)
+		return "", err
+	}
+
+	// "." means local repository
+	if b, ok := cfg.Branches[br]; ok && b.Remote != "" && b.Remote != "." {
+		return b.Remote, nil
+	}
+
+	if r := cfg.Raw.Section(remoteSection).Option(remoteOption); r != "" {
+		return r, nil
+	}
+
+	target := lg.targetRemote
+	if target == "" {
+		target = upstreamRemote
+	}
+
+	var names []string
+	for name := range cfg.Remotes {
+		if name != target {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		if _, ok := cfg.Remotes[target]; ok {
+			return target, nil
+		}
+
+		return "", errors.New("no remote in git repo")
+	}
+
+	sort.Strings(names)
+
+	projects := map[string]bool{}
+	for _, name := range names {
+		urls := cfg.Remotes[name].URLs
+		if len(urls) > 0 {
+			projects[remoteProject(urls[0])] = true
+		}
+	}
+
+	if len(projects) > 1 {
+		return "", fmt.Errorf("%w: remotes %s point to different projects, "+
+			"set upstream of branch, use --remote flag or \"git config %s.%s NAME\"",
+			ErrAmbiguousRemote, strings.Join(names, ", "), remoteSection, remoteOption)
+	}
+
+	for _, name := range names {
+		if name == originRemote {
+			return name, nil
+		}
+	}
+
+	return names[0], nil
+}
+
+// TargetRemote returns URL of configured target remote, "upstream" remote
+// or, if there is no one, the same remote as Remote.
+func (lg *LocalGit) TargetRemote() (string, error) {
+	name, err := lg.targetRemoteName()
+	if err != nil {
+		return "", err
+	}
+
+	return lg.remoteURL(name)
+}
+
+func (lg *LocalGit) targetRemoteName() (string, error) {
+	if lg.targetRemote != "" {
+		return lg.targetRemote, nil
+	}
+
+	_, err := lg.repo.Remote(upstreamRemote)
+	if err == nil {
+		return upstreamRemote, nil
+	}
+
+	return lg.remoteName()
+}
+
+func (lg *LocalGit) remoteURL(name string) (string, error) {
+	r, err := lg.repo.Remote(name)
+	if err != nil {
+		return "", fmt.Errorf("can not find remote %s: %w", name, err)
 	}
 
 	c := r.Config()
 	if len(c.URLs) < 1 {
-		return "", fmt.Errorf("no remote in git repo")
+		return "", fmt.Errorf("no url for remote %s in git repo", name)
 	}
 
 	return c.URLs[0], nil
@@ -44,3 +186,17 @@ func (lg *LocalGit) CurrentBranch() (string, error) {
 	refName := r.Name()
 	return refName.Short(), nil
 }
+
+// remoteProject returns host and path of remote URL, so the same project
+// with ssh and https remotes is detected.
+func remoteProject(u string) string {
+	e, err := transport.NewEndpoint(u)
+	if err != nil {
+		return u
+	}
+
+	p := strings.Trim(e.Path, "/")
+	p = strings.TrimSuffix(p, ".git")
+
+	return strings.ToLower(e.Host + "/" + p)
+}
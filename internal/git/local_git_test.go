@@ -0,0 +1,95 @@
+package git
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+func TestLocalGit_Remote(t *testing.T) {
+	r, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/feature"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = r.Storer.SetReference(plumbing.NewHashReference("refs/heads/feature", plumbing.NewHash("1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remotes := map[string]string{
+		"gl":       "git@gitlab.com:group/project.git",
+		"gl-https": "https://gitlab.com/group/project",
+		"upstream": "git@gitlab.com:upstream/project.git",
+	}
+	for name, u := range remotes {
+		_, err = r.CreateRemote(&config.RemoteConfig{Name: name, URLs: []string{u}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lg := &LocalGit{repo: r}
+
+	// the same project in all remotes except upstream
+	name, u, err := lg.Remote()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name != "gl" || u != remotes["gl"] {
+		t.Fatal("wrong remote", name, u)
+	}
+
+	_, err = r.CreateRemote(&config.RemoteConfig{Name: "other", URLs: []string{"git@gitlab.com:other/project.git"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = lg.Remote()
+	if !errors.Is(err, ErrAmbiguousRemote) {
+		t.Fatal("expected ErrAmbiguousRemote, got", err)
+	}
+
+	cfg, err := r.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Raw.Section(remoteSection).SetOption(remoteOption, "other")
+	err = r.SetConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, _, err = lg.Remote()
+	if err != nil || name != "other" {
+		t.Fatal("expected remote from git config, got", name, err)
+	}
+
+	cfg.Branches["feature"] = &config.Branch{Name: "feature", Remote: "gl-https", Merge: "refs/heads/feature"}
+	err = r.SetConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, _, err = lg.Remote()
+	if err != nil || name != "gl-https" {
+		t.Fatal("expected tracking remote, got", name, err)
+	}
+
+	lg.remote = "upstream"
+	name, _, err = lg.Remote()
+	if err != nil || name != "upstream" {
+		t.Fatal("expected configured remote, got", name, err)
+	}
+}
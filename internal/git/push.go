@@ -0,0 +1,183 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/rs/zerolog/log"
+)
+
+// tokenUsername is a username GitLab accepts with personal access token
+// for git over HTTPS.
+const tokenUsername = "oauth2"
+
+// Push pushes branch to remote and sets remote's branch as branch's upstream.
+// SSH remotes are authenticated by SSH agent, HTTP(S) remotes on GitLab's host
+// by GitLab token and other HTTP(S) remotes by git's credential helpers.
+func (lg *LocalGit) Push(ctx context.Context, remote, branch string) error {
+	u, err := lg.remoteURL(remote)
+	if err != nil {
+		return err
+	}
+
+	auth, err := lg.pushAuth(ctx, u)
+	if err != nil {
+		return err
+	}
+
+	ref := plumbing.NewBranchReferenceName(branch)
+	err = lg.repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{config.RefSpec(ref + ":" + ref)},
+		Auth:       auth,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("can not push %s to %s: %w", branch, remote, err)
+	}
+
+	return lg.setUpstream(remote, branch)
+}
+
+func (lg *LocalGit) pushAuth(ctx context.Context, remoteURL string) (transport.AuthMethod, error) {
+	e, err := transport.NewEndpoint(remoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("can not parse remote url: %w", err)
+	}
+
+	switch e.Protocol {
+	case "ssh":
+		auth, err := ssh.NewSSHAgentAuth(e.User)
+		if err != nil {
+			return nil, fmt.Errorf("can not connect to ssh agent: %w", err)
+		}
+
+		return auth, nil
+	case "http", "https":
+		if e.User != "" {
+			// credentials from url are used
+			return nil, nil
+		}
+
+		if lg.token == "" || !strings.EqualFold(e.Host, lg.gitLabHost) {
+			// GitLab token must not leak to other hosts
+			return credentialAuth(ctx, e), nil
+		}
+
+		return &http.BasicAuth{
+			Username: tokenUsername,
+			Password: lg.token,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// credentialAuth asks git's credential helpers for username and password of
+// endpoint, nil is returned if helpers have none and push goes unauthenticated.
+func credentialAuth(ctx context.Context, e *transport.Endpoint) transport.AuthMethod {
+	host := e.Host
+	if e.Port != 0 {
+		host += ":" + strconv.Itoa(e.Port)
+	}
+
+	in := fmt.Sprintf("protocol=%s\nhost=%s\n\n", e.Protocol, host)
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(in)
+	cmd.Stdout = &stdout
+	// never ask user in terminal, glmt output could be piped
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	err := cmd.Run()
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("host", host).Msg("no git credentials for remote")
+		return nil
+	}
+
+	auth := &http.BasicAuth{}
+	for _, l := range strings.Split(stdout.String(), "\n") {
+		switch {
+		case strings.HasPrefix(l, "username="):
+			auth.Username = strings.TrimPrefix(l, "username=")
+		case strings.HasPrefix(l, "password="):
+			auth.Password = strings.TrimPrefix(l, "password=")
+		}
+	}
+
+	if auth.Password == "" {
+		return nil
+	}
+
+	return auth
+}
+
+func (lg *LocalGit) setUpstream(remote, branch string) error {
+	cfg, err := lg.repo.Config()
+	if err != nil {
+		return fmt.Errorf("can not read git config: %w", err)
+	}
+
+	cfg.Branches[branch] = &config.Branch{
+		Name:   branch,
+		Remote: remote,
+		Merge:  plumbing.NewBranchReferenceName(branch),
+	}
+
+	err = lg.repo.SetConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("can not set upstream of %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+// Unpushed reports if branch has commits missing in remote's branch. Branch
+// absent on remote is unpushed too. State of remote is known from last fetch.
+func (lg *LocalGit) Unpushed(remote, branch string) (bool, error) {
+	local, err := lg.repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return false, fmt.Errorf("can not find branch %s: %w", branch, err)
+	}
+
+	rr, err := lg.repo.Reference(plumbing.NewRemoteReferenceName(remote, branch), true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("can not find remote branch %s/%s: %w", remote, branch, err)
+	}
+
+	if local.Hash() == rr.Hash() {
+		return false, nil
+	}
+
+	lc, err := lg.repo.CommitObject(local.Hash())
+	if err != nil {
+		return false, fmt.Errorf("can not read commit %s: %w", local.Hash(), err)
+	}
+
+	rc, err := lg.repo.CommitObject(rr.Hash())
+	if err != nil {
+		return false, fmt.Errorf("can not read commit %s: %w", rr.Hash(), err)
+	}
+
+	pushed, err := lc.IsAncestor(rc)
+	if err != nil {
+		return false, fmt.Errorf("can not compare %s with %s/%s: %w", branch, remote, branch, err)
+	}
+
+	return !pushed, nil
+}
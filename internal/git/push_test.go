@@ -0,0 +1,95 @@
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+func TestLocalGit_Push(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := git.PlainInit(dir+"/remote.git", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := git.PlainInit(dir+"/local", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = r.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{dir + "/remote.git"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = wt.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lg := &LocalGit{repo: r}
+
+	unpushed, err := lg.Unpushed("origin", "master")
+	if err != nil || !unpushed {
+		t.Fatal("expected unpushed branch", unpushed, err)
+	}
+
+	err = lg.Push(context.Background(), "origin", "master")
+	if err != nil {
+		t.Fatal("push failed", err)
+	}
+
+	cfg, err := r.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b := cfg.Branches["master"]; b == nil || b.Remote != "origin" {
+		t.Fatal("upstream is not set", b)
+	}
+
+	unpushed, err = lg.Unpushed("origin", "master")
+	if err != nil || unpushed {
+		t.Fatal("expected pushed branch", unpushed, err)
+	}
+}
+
+func TestLocalGit_PushAuth(t *testing.T) {
+	lg := &LocalGit{gitLabHost: "gitlab.example.com", token: "secret"}
+
+	cases := []struct {
+		url   string
+		token bool
+	}{
+		{"https://gitlab.example.com/group/project.git", true},
+		{"https://GitLab.Example.com/group/project.git", true},
+		{"https://github.com/group/project.git", false},
+		{"https://user@gitlab.example.com/group/project.git", false},
+	}
+
+	for _, tc := range cases {
+		auth, err := lg.pushAuth(context.Background(), tc.url)
+		if err != nil {
+			t.Fatal(tc.url, err)
+		}
+
+		ba, ok := auth.(*http.BasicAuth)
+		if got := ok && ba.Password == lg.token; got != tc.token {
+			t.Errorf("%s: exp token %v, got auth %v", tc.url, tc.token, auth)
+		}
+	}
+}
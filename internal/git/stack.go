@@ -0,0 +1,146 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// BranchStack returns local branches branch is stacked on, starting from the branch
+// nearest to target and ending with branch itself. Branches are searched among
+// first parents of branch's commits missing in target.
+func (lg *LocalGit) BranchStack(branch, target string) ([]string, error) {
+	bc, tc, err := lg.branchAndTarget(branch, target)
+	if err != nil {
+		return nil, err
+	}
+
+	cs, err := missingCommits(bc, tc)
+	if err != nil {
+		return nil, fmt.Errorf("can not compare %s with %s: %w", branch, target, err)
+	}
+
+	missing := make(map[plumbing.Hash]bool, len(cs))
+	for _, c := range cs {
+		missing[c.Hash] = true
+	}
+
+	tips, err := lg.branchTips(branch, target)
+	if err != nil {
+		return nil, err
+	}
+
+	stack := []string{branch}
+	for c := bc; c.NumParents() > 0; {
+		p, err := c.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("can not read parent of %s: %w", c.Hash, err)
+		}
+
+		c = p
+		if !missing[c.Hash] {
+			break
+		}
+
+		// several branches on the same commit are one stack item
+		if names := tips[c.Hash]; len(names) > 0 {
+			stack = append([]string{names[0]}, stack...)
+		}
+	}
+
+	return stack, nil
+}
+
+// branchTips returns sorted names of local branches by their commits,
+// skipped branches are not returned.
+func (lg *LocalGit) branchTips(skip ...string) (map[plumbing.Hash][]string, error) {
+	iter, err := lg.repo.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("can not list branches: %w", err)
+	}
+
+	tips := map[plumbing.Hash][]string{}
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		for _, s := range skip {
+			if name == s {
+				return nil
+			}
+		}
+
+		tips[ref.Hash()] = append(tips[ref.Hash()], name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can not list branches: %w", err)
+	}
+
+	for _, names := range tips {
+		sort.Strings(names)
+	}
+
+	return tips, nil
+}
+
+// RemoteBranches returns sorted names of target remote's branches
+// fetched to local repository.
+func (lg *LocalGit) RemoteBranches() ([]string, error) {
+	remote, err := lg.targetRemoteName()
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := lg.repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("can not list references: %w", err)
+	}
+
+	prefix := "refs/remotes/" + remote + "/"
+
+	var names []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().String()
+		if ref.Type() != plumbing.HashReference || !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+
+		names = append(names, strings.TrimPrefix(name, prefix))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can not list references: %w", err)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// Upstream returns name of branch's upstream branch if it is tracked from target
+// remote, otherwise it returns empty string.
+func (lg *LocalGit) Upstream(branch string) (string, error) {
+	remote, err := lg.targetRemoteName()
+	if err != nil {
+		return "", err
+	}
+
+	return lg.trackedBranch(remote, branch)
+}
+
+// trackedBranch returns name of remote's branch configured as branch's upstream,
+// it is empty if branch does not track remote's branch.
+func (lg *LocalGit) trackedBranch(remote, branch string) (string, error) {
+	cfg, err := lg.repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("can not read git config: %w", err)
+	}
+
+	b, ok := cfg.Branches[branch]
+	if !ok || b.Remote != remote || !b.Merge.IsBranch() {
+		return "", nil
+	}
+
+	return b.Merge.Short(), nil
+}
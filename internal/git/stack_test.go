@@ -0,0 +1,83 @@
+package git
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestLocalGit_BranchStack(t *testing.T) {
+	dir := t.TempDir()
+
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit := func(file string) {
+		err := ioutil.WriteFile(filepath.Join(dir, file), []byte(file), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = wt.Add(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = wt.Commit(file, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	branch := func(name string) {
+		err := wt.Checkout(&git.CheckoutOptions{
+			Branch: plumbing.NewBranchReferenceName(name),
+			Create: true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commit("base")
+	branch("other")
+	branch("a")
+	commit("a1")
+	branch("b")
+	commit("b1")
+	branch("c")
+	commit("c1")
+	commit("c2")
+
+	lg := &LocalGit{repo: r}
+
+	stack, err := lg.BranchStack("c", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(stack, exp) {
+		t.Fatalf("exp stack: %v, got: %v", exp, stack)
+	}
+
+	stack, err = lg.BranchStack("b", "a")
+	if err != nil || !reflect.DeepEqual(stack, []string{"b"}) {
+		t.Fatal("wrong stack on a", stack, err)
+	}
+}
@@ -3,6 +3,10 @@ package gitlab
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,6 +20,12 @@ type CreateMRRequest struct {
 	RemoveSourceBranch bool   `json:"remove_source_branch"`
 	AssigneeID         int    `json:"assignee_id"`
 	Labels             string `json:"labels"`
+	// AssigneeIDs replaces AssigneeID if not empty.
+	AssigneeIDs []int `json:"assignee_ids,omitempty"`
+	ReviewerIDs []int `json:"reviewer_ids,omitempty"`
+	// TargetProjectID is set when MR is created from fork, Project is fork then.
+	TargetProjectID int64 `json:"target_project_id,omitempty"`
+	MilestoneID     int64 `json:"milestone_id,omitempty"`
 }
 
 type CreateMRResponse struct {
@@ -31,12 +41,253 @@ type CreateMRResponse struct {
 	ChangesCount string `json:"changes_count"`
 }
 
+// UpdateMRRequest changes MR's attributes, empty values are not changed.
+type UpdateMRRequest struct {
+	Project      string `json:"id"`
+	IID          int64  `json:"merge_request_iid"`
+	Title        string `json:"title,omitempty"`
+	Description  string `json:"description,omitempty"`
+	TargetBranch string `json:"target_branch,omitempty"`
+	Labels       string `json:"labels,omitempty"`
+	AssigneeIDs  []int  `json:"assignee_ids,omitempty"`
+	ReviewerIDs  []int  `json:"reviewer_ids,omitempty"`
+	MilestoneID  int64  `json:"milestone_id,omitempty"`
+}
+
+// MergeMRRequest accepts MR. Empty values are not sent, so MR's settings are used.
+type MergeMRRequest struct {
+	Project                   string `json:"id"`
+	IID                       int64  `json:"merge_request_iid"`
+	MergeCommitMessage        string `json:"merge_commit_message,omitempty"`
+	SquashCommitMessage       string `json:"squash_commit_message,omitempty"`
+	Squash                    bool   `json:"squash,omitempty"`
+	ShouldRemoveSourceBranch  bool   `json:"should_remove_source_branch,omitempty"`
+	MergeWhenPipelineSucceeds bool   `json:"merge_when_pipeline_succeeds,omitempty"`
+	// SHA if set must match MR's HEAD, so MR is not merged if it was changed after checks.
+	SHA string `json:"sha,omitempty"`
+}
+
+// ListMRsRequest filters merge requests. Zero values are not sent to GitLab.
+type ListMRsRequest struct {
+	// Project limits search to one project, if empty MRs from all projects
+	// visible to current user are listed.
+	Project       string
+	State         string
+	Scope         string
+	AuthorID      int
+	AssigneeID    int
+	ReviewerID    int
+	SourceBranch  string
+	TargetBranch  string
+	Labels        []string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+}
+
+// MergeRequest is a merge request as GitLab returns it from MR's API.
+type MergeRequest struct {
+	ID              int64          `json:"id"`
+	IID             int64          `json:"iid"`
+	ProjectID       int64          `json:"project_id"`
+	SourceProjectID int64          `json:"source_project_id"`
+	TargetProjectID int64          `json:"target_project_id"`
+	Title           string         `json:"title"`
+	Description     string         `json:"description"`
+	State           string         `json:"state"`
+	SourceBranch    string         `json:"source_branch"`
+	TargetBranch    string         `json:"target_branch"`
+	Author          UserResponse   `json:"author"`
+	Assignees       []UserResponse `json:"assignees"`
+	Reviewers       []UserResponse `json:"reviewers"`
+	Draft           bool           `json:"draft"`
+	WorkInProgress  bool           `json:"work_in_progress"`
+	Labels          []string       `json:"labels"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	URL             string         `json:"web_url"`
+	References      References     `json:"references"`
+	// ChangesCount is not returned in MR's list, see CreateMRResponse.ChangesCount.
+	ChangesCount string `json:"changes_count"`
+	SHA          string `json:"sha"`
+	HasConflicts bool   `json:"has_conflicts"`
+	// BlockingDiscussionsResolved is true if all discussions required
+	// to be resolved are resolved.
+	BlockingDiscussionsResolved bool `json:"blocking_discussions_resolved"`
+	MergeWhenPipelineSucceeds   bool `json:"merge_when_pipeline_succeeds"`
+	// DivergedCommitsCount is a number of target branch's commits missing
+	// in source branch, only returned for a single MR request.
+	DivergedCommitsCount int `json:"diverged_commits_count"`
+	// HeadPipeline is only returned for a single MR request.
+	HeadPipeline *Pipeline `json:"head_pipeline"`
+	// MergeCommitSHA and SquashCommitSHA are empty if MR is not merged or
+	// merged without such commit.
+	MergeCommitSHA  string     `json:"merge_commit_sha"`
+	SquashCommitSHA string     `json:"squash_commit_sha"`
+	MergedAt        *time.Time `json:"merged_at"`
+}
+
+type References struct {
+	// Full is reference with project path, like group/project!1.
+	Full string `json:"full"`
+}
+
+type Issue struct {
+	ID          int64    `json:"id"`
+	IID         int64    `json:"iid"`
+	ProjectID   int64    `json:"project_id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	State       string   `json:"state"`
+	Labels      []string `json:"labels"`
+	// Milestone is nil if issue has no milestone.
+	Milestone *Milestone `json:"milestone"`
+	URL       string     `json:"web_url"`
+}
+
+type Milestone struct {
+	ID    int64  `json:"id"`
+	IID   int64  `json:"iid"`
+	Title string `json:"title"`
+}
+
+type Pipeline struct {
+	ID     int64  `json:"id"`
+	SHA    string `json:"sha"`
+	Ref    string `json:"ref"`
+	Status string `json:"status"`
+	URL    string `json:"web_url"`
+}
+
+type Job struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Stage        string `json:"stage"`
+	Status       string `json:"status"`
+	AllowFailure bool   `json:"allow_failure"`
+	URL          string `json:"web_url"`
+}
+
+type Discussion struct {
+	ID    string `json:"id"`
+	Notes []Note `json:"notes"`
+}
+
+type Note struct {
+	ID         int64        `json:"id"`
+	Body       string       `json:"body"`
+	Author     UserResponse `json:"author"`
+	System     bool         `json:"system"`
+	Resolvable bool         `json:"resolvable"`
+	Resolved   bool         `json:"resolved"`
+}
+
+type Approvals struct {
+	ApprovalsRequired int `json:"approvals_required"`
+	ApprovalsLeft     int `json:"approvals_left"`
+	ApprovedBy        []struct {
+		User UserResponse `json:"user"`
+	} `json:"approved_by"`
+}
+
+// ErrorKind classifies GitLab's errors.
+type ErrorKind int
+
+const (
+	ErrorUnknown ErrorKind = iota
+	ErrorUnauthorized
+	ErrorForbidden
+	ErrorNotFound
+	ErrorConflict
+	ErrorValidation
+	ErrorRateLimited
+	ErrorServer
+)
+
+// GitlabError is an error returned by GitLab's API.
 type GitlabError struct {
+	// StatusCode is HTTP status of response.
+	StatusCode int
+	// Method and Endpoint (path relative to API root) of failed request.
+	Method   string
+	Endpoint string
+	// Message is a main error message.
 	Message string
+	// Messages contains all messages if GitLab returned list of messages.
+	Messages []string
+	// Fields contains validation errors by attribute name.
+	Fields map[string][]string
+	// Description is an OAuth error description, like "Token is expired".
+	Description string
+	// RetryAfter is a delay GitLab asked to wait before retry, zero if none.
+	RetryAfter time.Duration
 }
 
 func (e GitlabError) Error() string {
-	return e.Message
+	sb := strings.Builder{}
+
+	if e.Endpoint != "" {
+		_, _ = sb.WriteString(e.Method + " " + e.Endpoint + ": ")
+	}
+
+	if e.StatusCode != 0 {
+		_, _ = sb.WriteString(strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode) + ": ")
+	}
+
+	_, _ = sb.WriteString(e.Message)
+
+	if e.Description != "" {
+		_, _ = sb.WriteString(" (" + e.Description + ")")
+	}
+
+	return sb.String()
+}
+
+// Kind classifies error by HTTP status.
+func (e GitlabError) Kind() ErrorKind {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrorUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return ErrorForbidden
+	case e.StatusCode == http.StatusNotFound:
+		return ErrorNotFound
+	case e.StatusCode == http.StatusConflict:
+		return ErrorConflict
+	case e.StatusCode == http.StatusBadRequest,
+		e.StatusCode == http.StatusUnprocessableEntity:
+		return ErrorValidation
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrorRateLimited
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrorServer
+	}
+
+	return ErrorUnknown
+}
+
+// IsUnauthorized reports missing, invalid, revoked or expired token.
+func (e GitlabError) IsUnauthorized() bool { return e.Kind() == ErrorUnauthorized }
+
+func (e GitlabError) IsForbidden() bool { return e.Kind() == ErrorForbidden }
+
+func (e GitlabError) IsNotFound() bool { return e.Kind() == ErrorNotFound }
+
+// IsConflict reports conflicting resource, like already existing MR.
+func (e GitlabError) IsConflict() bool { return e.Kind() == ErrorConflict }
+
+// IsValidation reports bad request parameters.
+func (e GitlabError) IsValidation() bool { return e.Kind() == ErrorValidation }
+
+func (e GitlabError) IsRateLimited() bool { return e.Kind() == ErrorRateLimited }
+
+func (e GitlabError) IsServerError() bool { return e.Kind() == ErrorServer }
+
+// AsGitlabError finds GitlabError in err's chain.
+func AsGitlabError(err error) (GitlabError, bool) {
+	var ge GitlabError
+	ok := errors.As(err, &ge)
+	return ge, ok
 }
 
 type UserResponse struct {
@@ -46,7 +297,84 @@ type UserResponse struct {
 	Name     string `json:"name"`
 }
 
+type Project struct {
+	ID                int64  `json:"id"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	URL               string `json:"web_url"`
+	DefaultBranch     string `json:"default_branch"`
+	// ForkedFromProject is nil if project is not a fork.
+	ForkedFromProject *Project `json:"forked_from_project"`
+}
+
+type Label struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type ReleaseRequest struct {
+	Project     string `json:"id"`
+	TagName     string `json:"tag_name"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description"`
+}
+
+type Release struct {
+	TagName     string       `json:"tag_name"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Links       ReleaseLinks `json:"_links"`
+}
+
+type ReleaseLinks struct {
+	Self string `json:"self"`
+}
+
+// Commit is a commit of MR.
+type Commit struct {
+	ID      string `json:"id"`
+	ShortID string `json:"short_id"`
+	Title   string `json:"title"`
+}
+
+// TreeEntry is a file or directory of repository tree.
+type TreeEntry struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Type is "blob" for files and "tree" for directories.
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
 type GitLab interface {
 	CreateMR(ctx context.Context, req CreateMRRequest) (CreateMRResponse, error)
 	CurrentUser(ctx context.Context) (UserResponse, error)
+	// UserByUsername finds user by username (without @).
+	UserByUsername(ctx context.Context, username string) (UserResponse, error)
+	// ListMRs returns all pages of merge requests matching request.
+	ListMRs(ctx context.Context, req ListMRsRequest) ([]MergeRequest, error)
+	// MentionedMRs returns merge requests from current user's pending todos
+	// where user was mentioned.
+	MentionedMRs(ctx context.Context) ([]MergeRequest, error)
+	// GetMR returns single merge request, project can be path or ID.
+	GetMR(ctx context.Context, project string, iid int64) (MergeRequest, error)
+	MRApprovals(ctx context.Context, project string, iid int64) (Approvals, error)
+	MRDiscussions(ctx context.Context, project string, iid int64) ([]Discussion, error)
+	// MRCommits returns commits of merge request, newest first.
+	MRCommits(ctx context.Context, project string, iid int64) ([]Commit, error)
+	PipelineJobs(ctx context.Context, project string, pipelineID int64) ([]Job, error)
+	UpdateMR(ctx context.Context, req UpdateMRRequest) (MergeRequest, error)
+	MergeMR(ctx context.Context, req MergeMRRequest) (MergeRequest, error)
+	// Project returns project by path or ID.
+	Project(ctx context.Context, project string) (Project, error)
+	Issue(ctx context.Context, project string, iid int64) (Issue, error)
+	// RepositoryTree lists entries of directory path at ref, empty ref means default branch.
+	RepositoryTree(ctx context.Context, project, path, ref string) ([]TreeEntry, error)
+	// RepositoryFile returns content of file at ref, empty ref means HEAD.
+	RepositoryFile(ctx context.Context, project, path, ref string) ([]byte, error)
+	// ProjectLabels returns labels of project including labels of its groups.
+	ProjectLabels(ctx context.Context, project string) ([]Label, error)
+	// CreateRelease creates release of existing tag.
+	CreateRelease(ctx context.Context, req ReleaseRequest) (Release, error)
+	// UpdateRelease updates release of tag.
+	UpdateRelease(ctx context.Context, req ReleaseRequest) (Release, error)
 }
@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 
 	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
 )
@@ -48,6 +49,138 @@ func (gl *DryRunGitLab) CurrentUser(ctx context.Context) (gitlab.UserResponse, e
 	return gitlab.UserResponse{}, nil
 }
 
+func (gl *DryRunGitLab) UserByUsername(ctx context.Context, username string) (gitlab.UserResponse, error) {
+	path, query := userByUsernameQuery(username)
+	return gitlab.UserResponse{Username: username}, gl.writeGet(ctx, "find user", path, query)
+}
+
+func (gl *DryRunGitLab) ListMRs(ctx context.Context, req gitlab.ListMRsRequest) ([]gitlab.MergeRequest, error) {
+	path, query := listMRsQuery(req)
+	return nil, gl.writeGet(ctx, "list merge requests", path, query)
+}
+
+func (gl *DryRunGitLab) MentionedMRs(ctx context.Context) ([]gitlab.MergeRequest, error) {
+	for _, action := range mentionActions {
+		path, query := mentionedMRsQuery(action)
+		err := gl.writeGet(ctx, "list todos", path, query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+func (gl *DryRunGitLab) GetMR(ctx context.Context, project string, iid int64) (gitlab.MergeRequest, error) {
+	path, query := getMRQuery(project, iid)
+	return gitlab.MergeRequest{}, gl.writeGet(ctx, "get merge request", path, query)
+}
+
+func (gl *DryRunGitLab) MRApprovals(ctx context.Context, project string, iid int64) (gitlab.Approvals, error) {
+	return gitlab.Approvals{}, gl.writeGet(ctx, "get approvals", mrPath(project, iid)+"/approvals", nil)
+}
+
+func (gl *DryRunGitLab) MRDiscussions(ctx context.Context, project string, iid int64) ([]gitlab.Discussion, error) {
+	return nil, gl.writeGet(ctx, "list discussions", mrPath(project, iid)+"/discussions", nil)
+}
+
+func (gl *DryRunGitLab) MRCommits(ctx context.Context, project string, iid int64) ([]gitlab.Commit, error) {
+	return nil, gl.writeGet(ctx, "list commits", mrPath(project, iid)+"/commits", nil)
+}
+
+func (gl *DryRunGitLab) PipelineJobs(ctx context.Context, project string, pipelineID int64) ([]gitlab.Job, error) {
+	return nil, gl.writeGet(ctx, "list pipeline jobs", pipelineJobsPath(project, pipelineID), nil)
+}
+
+func (gl *DryRunGitLab) UpdateMR(ctx context.Context, req gitlab.UpdateMRRequest) (gitlab.MergeRequest, error) {
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodPut, mrPath(req.Project, req.IID), nil, req)
+	if err != nil {
+		return gitlab.MergeRequest{}, err
+	}
+
+	_, _ = gl.out.WriteString("Sending update request:\n")
+	writeRequest(gl.out, hReq)
+
+	return gitlab.MergeRequest{
+		IID: req.IID,
+		URL: createMRURL(gl.host, req.Project, req.IID),
+	}, nil
+}
+
+func (gl *DryRunGitLab) MergeMR(ctx context.Context, req gitlab.MergeMRRequest) (gitlab.MergeRequest, error) {
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodPut, mrPath(req.Project, req.IID)+"/merge", nil, req)
+	if err != nil {
+		return gitlab.MergeRequest{}, err
+	}
+
+	_, _ = gl.out.WriteString("Sending merge request:\n")
+	writeRequest(gl.out, hReq)
+
+	return gitlab.MergeRequest{
+		IID: req.IID,
+		URL: createMRURL(gl.host, req.Project, req.IID),
+	}, nil
+}
+
+func (gl *DryRunGitLab) Project(ctx context.Context, project string) (gitlab.Project, error) {
+	return gitlab.Project{PathWithNamespace: project}, gl.writeGet(ctx, "get project", projectPath(project), nil)
+}
+
+func (gl *DryRunGitLab) Issue(ctx context.Context, project string, iid int64) (gitlab.Issue, error) {
+	return gitlab.Issue{}, gl.writeGet(ctx, "get issue", issuePath(project, iid), nil)
+}
+
+func (gl *DryRunGitLab) RepositoryTree(ctx context.Context, project, path, ref string) ([]gitlab.TreeEntry, error) {
+	p, q := repositoryTreeQuery(project, path, ref)
+	return nil, gl.writeGet(ctx, "repository tree", p, q)
+}
+
+func (gl *DryRunGitLab) RepositoryFile(ctx context.Context, project, path, ref string) ([]byte, error) {
+	p, q := repositoryFileQuery(project, path, ref)
+	return nil, gl.writeGet(ctx, "repository file", p, q)
+}
+
+func (gl *DryRunGitLab) ProjectLabels(ctx context.Context, project string) ([]gitlab.Label, error) {
+	p, q := projectLabelsQuery(project)
+	return nil, gl.writeGet(ctx, "project labels", p, q)
+}
+
+func (gl *DryRunGitLab) CreateRelease(ctx context.Context, req gitlab.ReleaseRequest) (gitlab.Release, error) {
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodPost, releasesPath(req.Project), nil, req)
+	if err != nil {
+		return gitlab.Release{}, err
+	}
+
+	_, _ = gl.out.WriteString("Sending create release request:\n")
+	writeRequest(gl.out, hReq)
+
+	return gitlab.Release{TagName: req.TagName, Name: req.Name, Description: req.Description}, nil
+}
+
+func (gl *DryRunGitLab) UpdateRelease(ctx context.Context, req gitlab.ReleaseRequest) (gitlab.Release, error) {
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodPut, releasePath(req.Project, req.TagName), nil, req)
+	if err != nil {
+		return gitlab.Release{}, err
+	}
+
+	_, _ = gl.out.WriteString("Sending update release request:\n")
+	writeRequest(gl.out, hReq)
+
+	return gitlab.Release{TagName: req.TagName, Name: req.Name, Description: req.Description}, nil
+}
+
+func (gl *DryRunGitLab) writeGet(ctx context.Context, name, path string, query url.Values) error {
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+
+	_, _ = gl.out.WriteString("Sending " + name + " request:\n")
+	writeRequest(gl.out, hReq)
+
+	return nil
+}
+
 func writeRequest(out io.StringWriter, r *http.Request) {
 	_, _ = out.WriteString(fmt.Sprintf("%v %v\n", r.Method, r.URL))
 
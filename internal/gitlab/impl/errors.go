@@ -0,0 +1,100 @@
+package impl
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+)
+
+const (
+	// maxRawErrorSize limits not JSON error body (like proxy's HTML page) in error message.
+	maxRawErrorSize = 200
+)
+
+// parseError parses GitLab's error response. GitLab returns errors as
+// {"message": "..."}, {"message": ["...", "..."]}, {"message": {"field": ["..."]}}
+// or {"error": "...", "error_description": "..."}.
+func parseError(hReq *http.Request, hResp *http.Response, body []byte) gitlab.GitlabError {
+	ge := gitlab.GitlabError{
+		StatusCode: hResp.StatusCode,
+		Method:     hReq.Method,
+		Endpoint:   strings.TrimPrefix(hReq.URL.Path, "/api/v4"),
+	}
+
+	if d, ok := serverDelay(hResp, time.Now()); ok {
+		ge.RetryAfter = d
+	}
+
+	var raw struct {
+		Message          json.RawMessage `json:"message"`
+		Error            string          `json:"error"`
+		ErrorDescription string          `json:"error_description"`
+	}
+
+	err := json.Unmarshal(body, &raw)
+	if err != nil {
+		ge.Message = strings.TrimSpace(string(body))
+		if len(ge.Message) > maxRawErrorSize {
+			ge.Message = ge.Message[:maxRawErrorSize] + "..."
+		}
+		if ge.Message == "" {
+			ge.Message = http.StatusText(hResp.StatusCode)
+		}
+
+		return ge
+	}
+
+	ge.Description = raw.ErrorDescription
+
+	var (
+		msg    string
+		msgs   []string
+		fields map[string][]string
+	)
+
+	switch {
+	case len(raw.Message) == 0:
+		ge.Message = raw.Error
+	case json.Unmarshal(raw.Message, &msg) == nil:
+		ge.Message = msg
+	case json.Unmarshal(raw.Message, &msgs) == nil:
+		ge.Messages = msgs
+		ge.Message = strings.Join(msgs, "; ")
+	case json.Unmarshal(raw.Message, &fields) == nil:
+		ge.Fields = fields
+		ge.Message = fieldsMessage(fields)
+	default:
+		ge.Message = string(raw.Message)
+	}
+
+	if ge.Message == "" {
+		ge.Message = http.StatusText(hResp.StatusCode)
+	}
+
+	return ge
+}
+
+// fieldsMessage joins validation errors in stable order.
+func fieldsMessage(fields map[string][]string) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	msgs := make([]string, 0, len(fields))
+	for _, name := range names {
+		msg := strings.Join(fields[name], ", ")
+		if name != "base" {
+			msg = name + " " + msg
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	return strings.Join(msgs, "; ")
+}
@@ -4,35 +4,134 @@ package impl
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
 
 	"github.com/rs/zerolog/log"
 )
 
-func NewHTTPGitLab(token, host string) *HTTPGitLab {
+const (
+	// pageSize is the maximum page size allowed by GitLab.
+	pageSize = 100
+)
+
+func NewHTTPGitLab(cfg config.GitLab) *HTTPGitLab {
+	policy := newRetryPolicy(cfg.Retry)
+
 	return &HTTPGitLab{
 		c: &http.Client{
-			Timeout: time.Second * 30,
+			// timeout is set for every attempt by transport
+			Transport: newRetryTransport(http.DefaultTransport, policy, time.Duration(cfg.Timeout)),
 		},
-		token: token,
-		host:  host,
+		token:  cfg.Token,
+		host:   cfg.URL,
+		policy: policy,
 	}
 }
 
 type HTTPGitLab struct {
-	c     *http.Client
-	token string
-	host  string
+	c      *http.Client
+	token  string
+	host   string
+	policy retryPolicy
 }
 
+// CreateMR is not idempotent so it is not retried by transport. On transient
+// error CreateMR checks if MR was created anyway and only then retries.
 func (gl *HTTPGitLab) CreateMR(ctx context.Context, req gitlab.CreateMRRequest) (gitlab.CreateMRResponse, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := gl.createMR(ctx, req)
+		if err == nil || attempt >= gl.policy.maxAttempts || !isTransientError(err) {
+			return resp, err
+		}
+
+		project, mrs, lerr := gl.createdMRs(ctx, req)
+		if lerr != nil {
+			return resp, err
+		}
+
+		if len(mrs) > 0 {
+			log.Ctx(ctx).Debug().
+				Err(err).
+				Str("url", mrs[0].URL).
+				Msg("mr is created despite error")
+
+			mr, err := gl.GetMR(ctx, project, mrs[0].IID)
+			if err != nil {
+				return resp, err
+			}
+
+			return gitlab.CreateMRResponse{
+				ID:           mr.ID,
+				IID:          mr.IID,
+				ProjectID:    mr.ProjectID,
+				CreatedAt:    mr.CreatedAt,
+				URL:          mr.URL,
+				ChangesCount: mr.ChangesCount,
+			}, nil
+		}
+
+		d := gl.policy.errorBackoff(attempt, err)
+		log.Ctx(ctx).Debug().
+			Err(err).
+			Int("attempt", attempt).
+			Dur("backoff", d).
+			Msg("retrying create mr")
+
+		err = sleep(ctx, d)
+		if err != nil {
+			return resp, err
+		}
+	}
+}
+
+// createdMRs lists opened MRs matching request and returns project they are in.
+// MR from fork is in target project, so MRs are matched by source project too.
+func (gl *HTTPGitLab) createdMRs(ctx context.Context, req gitlab.CreateMRRequest) (string, []gitlab.MergeRequest, error) {
+	lreq := gitlab.ListMRsRequest{
+		Project:      req.Project,
+		State:        "opened",
+		SourceBranch: req.SourceBranch,
+		TargetBranch: req.TargetBranch,
+	}
+
+	if req.TargetProjectID == 0 {
+		mrs, err := gl.ListMRs(ctx, lreq)
+		return req.Project, mrs, err
+	}
+
+	sp, err := gl.Project(ctx, req.Project)
+	if err != nil {
+		return "", nil, err
+	}
+
+	lreq.Project = strconv.FormatInt(req.TargetProjectID, 10)
+
+	mrs, err := gl.ListMRs(ctx, lreq)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var fmrs []gitlab.MergeRequest
+	for _, mr := range mrs {
+		if mr.SourceProjectID == sp.ID && mr.SourceBranch == req.SourceBranch {
+			fmrs = append(fmrs, mr)
+		}
+	}
+
+	return lreq.Project, fmrs, nil
+}
+
+func (gl *HTTPGitLab) createMR(ctx context.Context, req gitlab.CreateMRRequest) (gitlab.CreateMRResponse, error) {
 	var resp gitlab.CreateMRResponse
 
 	hReq, err := createHTTPRequest(ctx, gl.token, gl.host, req)
@@ -41,96 +140,461 @@ func (gl *HTTPGitLab) CreateMR(ctx context.Context, req gitlab.CreateMRRequest)
 	}
 
 	log.Ctx(ctx).Debug().
-		Stringer("url", hReq.URL).
-		Str("token", hideToken(gl.token)).
 		Interface("request", req).
-		Msg("post to gitlab")
+		Msg("create mr")
 
-	hResp, err := gl.c.Do(hReq)
+	err = gl.do(ctx, hReq, http.StatusCreated, &resp)
 	if err != nil {
 		return resp, fmt.Errorf("can not create mr in gitlab: %w", err)
 	}
 
-	defer hResp.Body.Close()
+	return resp, nil
+}
 
-	if hResp.StatusCode != http.StatusCreated {
-		// TODO: implement typed error
-		// type glerr  struct {
-		// 	Message map[string]interface{}
-		// 	Error string
-		// }
-		// err = json.NewDecoder(hResp.Body).Decode(&gerr)
+func (gl *HTTPGitLab) CurrentUser(ctx context.Context) (gitlab.UserResponse, error) {
+	var resp gitlab.UserResponse
 
-		errm, err := ioutil.ReadAll(hResp.Body)
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodGet, "/user", nil, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	err = gl.do(ctx, hReq, http.StatusOK, &resp)
+	if err != nil {
+		return resp, fmt.Errorf("can not get user from gitlab: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (gl *HTTPGitLab) UserByUsername(ctx context.Context, username string) (gitlab.UserResponse, error) {
+	var resp []gitlab.UserResponse
+
+	path, query := userByUsernameQuery(username)
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodGet, path, query, nil)
+	if err != nil {
+		return gitlab.UserResponse{}, err
+	}
+
+	err = gl.do(ctx, hReq, http.StatusOK, &resp)
+	if err != nil {
+		return gitlab.UserResponse{}, fmt.Errorf("can not find user %s in gitlab: %w", username, err)
+	}
+
+	if len(resp) == 0 {
+		return gitlab.UserResponse{}, fmt.Errorf("user %s not found in gitlab", username)
+	}
+
+	return resp[0], nil
+}
+
+func (gl *HTTPGitLab) ListMRs(ctx context.Context, req gitlab.ListMRsRequest) ([]gitlab.MergeRequest, error) {
+	var mrs []gitlab.MergeRequest
+
+	path, query := listMRsQuery(req)
+	err := gl.getPages(ctx, path, query, func(dec *json.Decoder) error {
+		var page []gitlab.MergeRequest
+		err := dec.Decode(&page)
+		mrs = append(mrs, page...)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can not list merge requests: %w", err)
+	}
+
+	return mrs, nil
+}
+
+func (gl *HTTPGitLab) MentionedMRs(ctx context.Context) ([]gitlab.MergeRequest, error) {
+	type todo struct {
+		Target gitlab.MergeRequest `json:"target"`
+	}
+
+	var mrs []gitlab.MergeRequest
+
+	for _, action := range mentionActions {
+		path, query := mentionedMRsQuery(action)
+		err := gl.getPages(ctx, path, query, func(dec *json.Decoder) error {
+			var page []todo
+			err := dec.Decode(&page)
+			for _, t := range page {
+				mrs = append(mrs, t.Target)
+			}
+			return err
+		})
 		if err != nil {
-			return resp, fmt.Errorf("can not decode error from gitlab's create MR: %w", err)
+			return nil, fmt.Errorf("can not list todos: %w", err)
 		}
-		return resp, gitlab.GitlabError{Message: string(errm)}
 	}
 
-	err = json.NewDecoder(hResp.Body).Decode(&resp)
+	return mrs, nil
+}
+
+func (gl *HTTPGitLab) GetMR(ctx context.Context, project string, iid int64) (gitlab.MergeRequest, error) {
+	var resp gitlab.MergeRequest
+
+	path, query := getMRQuery(project, iid)
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodGet, path, query, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	err = gl.do(ctx, hReq, http.StatusOK, &resp)
 	if err != nil {
-		return resp, fmt.Errorf("can not decode response from gitlab's create MR: %w", err)
+		return resp, fmt.Errorf("can not get merge request: %w", err)
 	}
 
 	return resp, nil
 }
 
-func (gl *HTTPGitLab) CurrentUser(ctx context.Context) (gitlab.UserResponse, error) {
-	var resp gitlab.UserResponse
+func (gl *HTTPGitLab) MRApprovals(ctx context.Context, project string, iid int64) (gitlab.Approvals, error) {
+	var resp gitlab.Approvals
+
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodGet, mrPath(project, iid)+"/approvals", nil, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	err = gl.do(ctx, hReq, http.StatusOK, &resp)
+	if err != nil {
+		return resp, fmt.Errorf("can not get merge request approvals: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (gl *HTTPGitLab) MRDiscussions(ctx context.Context, project string, iid int64) ([]gitlab.Discussion, error) {
+	var ds []gitlab.Discussion
+
+	err := gl.getPages(ctx, mrPath(project, iid)+"/discussions", url.Values{}, func(dec *json.Decoder) error {
+		var page []gitlab.Discussion
+		err := dec.Decode(&page)
+		ds = append(ds, page...)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can not list merge request discussions: %w", err)
+	}
+
+	return ds, nil
+}
+
+func (gl *HTTPGitLab) MRCommits(ctx context.Context, project string, iid int64) ([]gitlab.Commit, error) {
+	var cs []gitlab.Commit
+
+	err := gl.getPages(ctx, mrPath(project, iid)+"/commits", url.Values{}, func(dec *json.Decoder) error {
+		var page []gitlab.Commit
+		err := dec.Decode(&page)
+		cs = append(cs, page...)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can not list merge request commits: %w", err)
+	}
+
+	return cs, nil
+}
+
+func (gl *HTTPGitLab) PipelineJobs(ctx context.Context, project string, pipelineID int64) ([]gitlab.Job, error) {
+	var jobs []gitlab.Job
+
+	err := gl.getPages(ctx, pipelineJobsPath(project, pipelineID), url.Values{}, func(dec *json.Decoder) error {
+		var page []gitlab.Job
+		err := dec.Decode(&page)
+		jobs = append(jobs, page...)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can not list pipeline jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (gl *HTTPGitLab) UpdateMR(ctx context.Context, req gitlab.UpdateMRRequest) (gitlab.MergeRequest, error) {
+	var resp gitlab.MergeRequest
+
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodPut, mrPath(req.Project, req.IID), nil, req)
+	if err != nil {
+		return resp, err
+	}
+
+	err = gl.do(ctx, hReq, http.StatusOK, &resp)
+	if err != nil {
+		return resp, fmt.Errorf("can not update merge request: %w", err)
+	}
+
+	return resp, nil
+}
 
-	methodURL := fmt.Sprintf("%s/api/v4/user", gl.host)
-	hReq, err := http.NewRequestWithContext(ctx, http.MethodGet, methodURL, nil)
+func (gl *HTTPGitLab) MergeMR(ctx context.Context, req gitlab.MergeMRRequest) (gitlab.MergeRequest, error) {
+	var resp gitlab.MergeRequest
+
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodPut, mrPath(req.Project, req.IID)+"/merge", nil, req)
+	if err != nil {
+		return resp, err
+	}
+
+	err = gl.do(ctx, hReq, http.StatusOK, &resp)
+	if err != nil {
+		return resp, fmt.Errorf("can not merge merge request: %w", err)
+	}
+
+	return resp, nil
+}
+
+// Project returns project by path or ID.
+func (gl *HTTPGitLab) Project(ctx context.Context, project string) (gitlab.Project, error) {
+	var resp gitlab.Project
+
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodGet, projectPath(project), nil, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	err = gl.do(ctx, hReq, http.StatusOK, &resp)
 	if err != nil {
-		return resp, fmt.Errorf("can not create request for gitlab's user: %w", err)
+		return resp, fmt.Errorf("can not get project %s: %w", project, err)
 	}
 
-	hReq.Header.Set("Private-Token", gl.token)
+	return resp, nil
+}
+
+func (gl *HTTPGitLab) Issue(ctx context.Context, project string, iid int64) (gitlab.Issue, error) {
+	var resp gitlab.Issue
+
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodGet, issuePath(project, iid), nil, nil)
+	if err != nil {
+		return resp, err
+	}
+
+	err = gl.do(ctx, hReq, http.StatusOK, &resp)
+	if err != nil {
+		return resp, fmt.Errorf("can not get issue: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (gl *HTTPGitLab) RepositoryTree(ctx context.Context, project, path, ref string) ([]gitlab.TreeEntry, error) {
+	var entries []gitlab.TreeEntry
+
+	p, query := repositoryTreeQuery(project, path, ref)
+	err := gl.getPages(ctx, p, query, func(dec *json.Decoder) error {
+		var page []gitlab.TreeEntry
+		err := dec.Decode(&page)
+		entries = append(entries, page...)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can not list repository tree: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (gl *HTTPGitLab) RepositoryFile(ctx context.Context, project, path, ref string) ([]byte, error) {
+	var resp struct {
+		Encoding string `json:"encoding"`
+		Content  string `json:"content"`
+	}
+
+	p, query := repositoryFileQuery(project, path, ref)
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodGet, p, query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = gl.do(ctx, hReq, http.StatusOK, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("can not get repository file %s: %w", path, err)
+	}
+
+	if resp.Encoding != "base64" {
+		return []byte(resp.Content), nil
+	}
+
+	content, err := base64.StdEncoding.DecodeString(resp.Content)
+	if err != nil {
+		return nil, fmt.Errorf("can not decode repository file %s: %w", path, err)
+	}
+
+	return content, nil
+}
+
+func (gl *HTTPGitLab) ProjectLabels(ctx context.Context, project string) ([]gitlab.Label, error) {
+	var labels []gitlab.Label
+
+	p, query := projectLabelsQuery(project)
+	err := gl.getPages(ctx, p, query, func(dec *json.Decoder) error {
+		var page []gitlab.Label
+		err := dec.Decode(&page)
+		labels = append(labels, page...)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can not list project labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+func (gl *HTTPGitLab) CreateRelease(ctx context.Context, req gitlab.ReleaseRequest) (gitlab.Release, error) {
+	var resp gitlab.Release
+
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodPost, releasesPath(req.Project), nil, req)
+	if err != nil {
+		return resp, err
+	}
 
+	err = gl.do(ctx, hReq, http.StatusCreated, &resp)
+	if err != nil {
+		return resp, fmt.Errorf("can not create release: %w", err)
+	}
+
+	return resp, nil
+}
+
+func (gl *HTTPGitLab) UpdateRelease(ctx context.Context, req gitlab.ReleaseRequest) (gitlab.Release, error) {
+	var resp gitlab.Release
+
+	hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodPut, releasePath(req.Project, req.TagName), nil, req)
+	if err != nil {
+		return resp, err
+	}
+
+	err = gl.do(ctx, hReq, http.StatusOK, &resp)
+	if err != nil {
+		return resp, fmt.Errorf("can not update release: %w", err)
+	}
+
+	return resp, nil
+}
+
+// getPages requests all pages of path following GitLab's X-Next-Page
+// header, page is called to decode every page.
+func (gl *HTTPGitLab) getPages(
+	ctx context.Context,
+	path string,
+	query url.Values,
+	page func(dec *json.Decoder) error,
+) error {
+	query.Set("per_page", strconv.Itoa(pageSize))
+
+	next := "1"
+	for next != "" {
+		query.Set("page", next)
+
+		hReq, err := newHTTPRequest(ctx, gl.token, gl.host, http.MethodGet, path, query, nil)
+		if err != nil {
+			return err
+		}
+
+		hResp, err := gl.send(ctx, hReq, http.StatusOK)
+		if err != nil {
+			return err
+		}
+
+		err = page(json.NewDecoder(hResp.Body))
+		hResp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("can not decode response from gitlab: %w", err)
+		}
+
+		next = hResp.Header.Get("X-Next-Page")
+	}
+
+	return nil
+}
+
+// do sends request and decodes response into out.
+func (gl *HTTPGitLab) do(ctx context.Context, hReq *http.Request, expStatus int, out interface{}) error {
+	hResp, err := gl.send(ctx, hReq, expStatus)
+	if err != nil {
+		return err
+	}
+
+	defer hResp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+
+	err = json.NewDecoder(hResp.Body).Decode(out)
+	if err != nil {
+		return fmt.Errorf("can not decode response from gitlab: %w", err)
+	}
+
+	return nil
+}
+
+// send sends request and checks response status. Response body
+// should be closed by caller.
+func (gl *HTTPGitLab) send(ctx context.Context, hReq *http.Request, expStatus int) (*http.Response, error) {
 	log.Ctx(ctx).Debug().
+		Str("method", hReq.Method).
 		Stringer("url", hReq.URL).
 		Str("token", hideToken(gl.token)).
-		Msg("get to gitlab")
+		Msg("request to gitlab")
 
 	hResp, err := gl.c.Do(hReq)
 	if err != nil {
-		return resp, fmt.Errorf("can not get user from gitlab: %w", err)
+		return nil, err
 	}
 
-	defer hResp.Body.Close()
+	if hResp.StatusCode != expStatus {
+		defer hResp.Body.Close()
 
-	if hResp.StatusCode != http.StatusOK {
 		errm, err := ioutil.ReadAll(hResp.Body)
 		if err != nil {
-			return resp, fmt.Errorf("can not decode error from gitlab's get user: %w", err)
+			return nil, fmt.Errorf("can not read error from gitlab: %w", err)
 		}
-		return resp, gitlab.GitlabError{Message: string(errm)}
+		return nil, parseError(hReq, hResp, errm)
 	}
 
-	err = json.NewDecoder(hResp.Body).Decode(&resp)
+	return hResp, nil
+}
+
+func createHTTPRequest(ctx context.Context, token, host string, req gitlab.CreateMRRequest) (*http.Request, error) {
+	hReq, err := newHTTPRequest(ctx, token, host, http.MethodPost, projectPath(req.Project)+"/merge_requests", nil, req)
 	if err != nil {
-		return resp, fmt.Errorf("can not decode response from gitlab's get user: %w", err)
+		return nil, fmt.Errorf("can not create request for gitlab's create mr: %w", err)
 	}
 
-	return resp, nil
+	return hReq, nil
 }
 
-func createHTTPRequest(ctx context.Context, token, host string, req gitlab.CreateMRRequest) (*http.Request, error) {
+// newHTTPRequest creates request to GitLab's API v4, path is relative to API root.
+// Not nil body is encoded as JSON.
+func newHTTPRequest(
+	ctx context.Context,
+	token, host, method, path string,
+	query url.Values,
+	body interface{},
+) (*http.Request, error) {
 	data := &bytes.Buffer{}
-	enc := json.NewEncoder(data)
-	enc.SetIndent("", "  ")
-	err := enc.Encode(req)
-	if err != nil {
-		return nil, fmt.Errorf("can not encode request to gitlab: %w", err)
+	if body != nil {
+		enc := json.NewEncoder(data)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(body)
+		if err != nil {
+			return nil, fmt.Errorf("can not encode request to gitlab: %w", err)
+		}
 	}
 
-	methodURL := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests", host, url.PathEscape(req.Project))
-	hReq, err := http.NewRequestWithContext(ctx, http.MethodPost, methodURL, data)
+	methodURL := fmt.Sprintf("%s/api/v4%s", host, path)
+	if len(query) > 0 {
+		methodURL += "?" + query.Encode()
+	}
+
+	hReq, err := http.NewRequestWithContext(ctx, method, methodURL, data)
 	if err != nil {
-		return nil, fmt.Errorf("can not create request for gitlab's create mr: %w", err)
+		return nil, fmt.Errorf("can not create request to gitlab: %w", err)
 	}
 
-	hReq.Header.Set("Content-Type", "application/json")
+	if body != nil {
+		hReq.Header.Set("Content-Type", "application/json")
+	}
 	hReq.Header.Set("Private-Token", token)
 
 	return hReq, nil
@@ -0,0 +1,169 @@
+package impl_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab/impl"
+)
+
+func TestHTTPGitLab_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		exp    gitlab.GitlabError
+		kind   gitlab.ErrorKind
+	}{{
+		name:   "string message",
+		status: http.StatusNotFound,
+		body:   `{"message":"404 Project Not Found"}`,
+		exp: gitlab.GitlabError{
+			Message: "404 Project Not Found",
+		},
+		kind: gitlab.ErrorNotFound,
+	}, {
+		name:   "list message",
+		status: http.StatusConflict,
+		body:   `{"message":["Another open merge request already exists for this source branch: !1"]}`,
+		exp: gitlab.GitlabError{
+			Message:  "Another open merge request already exists for this source branch: !1",
+			Messages: []string{"Another open merge request already exists for this source branch: !1"},
+		},
+		kind: gitlab.ErrorConflict,
+	}, {
+		name:   "fields message",
+		status: http.StatusBadRequest,
+		body:   `{"message":{"title":["can't be blank"],"base":["is invalid"]}}`,
+		exp: gitlab.GitlabError{
+			Message: "is invalid; title can't be blank",
+			Fields: map[string][]string{
+				"title": {"can't be blank"},
+				"base":  {"is invalid"},
+			},
+		},
+		kind: gitlab.ErrorValidation,
+	}, {
+		name:   "oauth error",
+		status: http.StatusUnauthorized,
+		body:   `{"error":"invalid_token","error_description":"Token is expired. You can either do re-authorization or token refresh."}`,
+		exp: gitlab.GitlabError{
+			Message:     "invalid_token",
+			Description: "Token is expired. You can either do re-authorization or token refresh.",
+		},
+		kind: gitlab.ErrorUnauthorized,
+	}, {
+		name:   "not json",
+		status: http.StatusBadGateway,
+		body:   "<html>Bad Gateway</html>",
+		exp: gitlab.GitlabError{
+			Message: "<html>Bad Gateway</html>",
+		},
+		kind: gitlab.ErrorServer,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer ts.Close()
+
+			gl := impl.NewHTTPGitLab(config.GitLab{
+				Token: "token",
+				URL:   ts.URL,
+				Retry: config.Retry{MaxAttempts: 1},
+			})
+			_, err := gl.CreateMR(context.Background(), gitlab.CreateMRRequest{
+				Project: "group/project",
+			})
+
+			ge, ok := gitlab.AsGitlabError(err)
+			if !ok {
+				t.Fatalf("expected GitlabError, got %v", err)
+			}
+
+			tt.exp.StatusCode = tt.status
+			tt.exp.Method = http.MethodPost
+			tt.exp.Endpoint = "/projects/group/project/merge_requests"
+
+			if !reflect.DeepEqual(tt.exp, ge) {
+				t.Fatalf("expected error: %+v, got %+v", tt.exp, ge)
+			}
+
+			if ge.Kind() != tt.kind {
+				t.Fatalf("expected kind: %v, got %v", tt.kind, ge.Kind())
+			}
+		})
+	}
+}
+
+func TestHTTPGitLab_RepositoryFile(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fproject/repository/files/.gitlab%2Fmerge_request_templates%2FBug.md" {
+			t.Errorf("unexpected path: %s", r.URL.EscapedPath())
+		}
+		if ref := r.URL.Query().Get("ref"); ref != "master" {
+			t.Errorf("unexpected ref: %s", ref)
+		}
+
+		_, _ = w.Write([]byte(`{"encoding":"base64","content":"IyMgQnVnCg=="}`))
+	}))
+	defer ts.Close()
+
+	gl := impl.NewHTTPGitLab(config.GitLab{
+		Token: "token",
+		URL:   ts.URL,
+	})
+
+	content, err := gl.RepositoryFile(context.Background(), "group/project", ".gitlab/merge_request_templates/Bug.md", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "## Bug\n" {
+		t.Fatalf("unexpected content: %q", content)
+	}
+}
+
+func TestHTTPGitLab_UpdateRelease(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.EscapedPath() != "/api/v4/projects/group%2Fproject/releases/release%2F1.2" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.EscapedPath())
+		}
+
+		var req gitlab.ReleaseRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil || req.Description != "notes" {
+			t.Errorf("unexpected body: %+v, %v", req, err)
+		}
+
+		_, _ = w.Write([]byte(`{"tag_name":"release/1.2","description":"notes","_links":{"self":"https://gitlab.com/release"}}`))
+	}))
+	defer ts.Close()
+
+	gl := impl.NewHTTPGitLab(config.GitLab{
+		Token: "token",
+		URL:   ts.URL,
+	})
+
+	r, err := gl.UpdateRelease(context.Background(), gitlab.ReleaseRequest{
+		Project:     "group/project",
+		TagName:     "release/1.2",
+		Description: "notes",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Links.Self != "https://gitlab.com/release" {
+		t.Fatalf("unexpected release: %+v", r)
+	}
+}
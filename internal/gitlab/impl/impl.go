@@ -1,6 +1,22 @@
 // Package impl implements services for gitlab
 package impl
 
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+)
+
+const (
+	timeFormat = "2006-01-02T15:04:05Z07:00"
+)
+
+// mentionActions are todo actions created when user is mentioned.
+var mentionActions = []string{"mentioned", "directly_addressed"}
+
 func hideToken(s string) string {
 	if s == "" {
 		return s
@@ -12,3 +28,117 @@ func hideToken(s string) string {
 
 	return s[:3] + "..."
 }
+
+func projectPath(project string) string {
+	return "/projects/" + url.PathEscape(project)
+}
+
+func mrPath(project string, iid int64) string {
+	return fmt.Sprintf("%s/merge_requests/%d", projectPath(project), iid)
+}
+
+func releasesPath(project string) string {
+	return projectPath(project) + "/releases"
+}
+
+func releasePath(project, tag string) string {
+	return releasesPath(project) + "/" + url.PathEscape(tag)
+}
+
+func issuePath(project string, iid int64) string {
+	return fmt.Sprintf("%s/issues/%d", projectPath(project), iid)
+}
+
+func repositoryTreeQuery(project, path, ref string) (string, url.Values) {
+	query := url.Values{}
+	setQuery(query, "path", path)
+	setQuery(query, "ref", ref)
+
+	return projectPath(project) + "/repository/tree", query
+}
+
+func repositoryFileQuery(project, path, ref string) (string, url.Values) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	query := url.Values{}
+	query.Set("ref", ref)
+
+	return projectPath(project) + "/repository/files/" + url.PathEscape(path), query
+}
+
+func projectLabelsQuery(project string) (string, url.Values) {
+	query := url.Values{}
+	query.Set("include_ancestor_groups", "true")
+
+	return projectPath(project) + "/labels", query
+}
+
+func getMRQuery(project string, iid int64) (string, url.Values) {
+	query := url.Values{}
+	query.Set("include_diverged_commits_count", "true")
+
+	return mrPath(project, iid), query
+}
+
+func pipelineJobsPath(project string, pipelineID int64) string {
+	return fmt.Sprintf("%s/pipelines/%d/jobs", projectPath(project), pipelineID)
+}
+
+func listMRsQuery(req gitlab.ListMRsRequest) (string, url.Values) {
+	path := "/merge_requests"
+	if req.Project != "" {
+		path = projectPath(req.Project) + path
+	}
+
+	query := url.Values{}
+	setQuery(query, "state", req.State)
+	setQuery(query, "scope", req.Scope)
+	setQuery(query, "source_branch", req.SourceBranch)
+	setQuery(query, "target_branch", req.TargetBranch)
+	setQuery(query, "labels", strings.Join(req.Labels, ","))
+
+	if req.AuthorID != 0 {
+		query.Set("author_id", strconv.Itoa(req.AuthorID))
+	}
+	if req.AssigneeID != 0 {
+		query.Set("assignee_id", strconv.Itoa(req.AssigneeID))
+	}
+	if req.ReviewerID != 0 {
+		query.Set("reviewer_id", strconv.Itoa(req.ReviewerID))
+	}
+	if !req.CreatedAfter.IsZero() {
+		query.Set("created_after", req.CreatedAfter.UTC().Format(timeFormat))
+	}
+	if !req.CreatedBefore.IsZero() {
+		query.Set("created_before", req.CreatedBefore.UTC().Format(timeFormat))
+	}
+	if !req.UpdatedAfter.IsZero() {
+		query.Set("updated_after", req.UpdatedAfter.UTC().Format(timeFormat))
+	}
+
+	return path, query
+}
+
+func userByUsernameQuery(username string) (string, url.Values) {
+	query := url.Values{}
+	query.Set("username", strings.TrimPrefix(username, "@"))
+
+	return "/users", query
+}
+
+func mentionedMRsQuery(action string) (string, url.Values) {
+	query := url.Values{}
+	query.Set("type", "MergeRequest")
+	query.Set("state", "pending")
+	query.Set("action", action)
+
+	return "/todos", query
+}
+
+func setQuery(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
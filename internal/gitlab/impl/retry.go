@@ -0,0 +1,318 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+)
+
+const (
+	defaultTimeout     = 30 * time.Second
+	defaultMaxAttempts = 3
+	defaultMinBackoff  = 500 * time.Millisecond
+	defaultMaxBackoff  = 10 * time.Second
+	// maxServerDelay caps delay requested by GitLab, so glmt does not hang
+	// for long rate limit windows.
+	maxServerDelay = time.Minute
+)
+
+// retryPolicy tells when and how long to wait before next attempt.
+type retryPolicy struct {
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+}
+
+func newRetryPolicy(cfg config.Retry) retryPolicy {
+	rp := retryPolicy{
+		maxAttempts: cfg.MaxAttempts,
+		minBackoff:  time.Duration(cfg.MinBackoff),
+		maxBackoff:  time.Duration(cfg.MaxBackoff),
+	}
+
+	if rp.maxAttempts <= 0 {
+		rp.maxAttempts = defaultMaxAttempts
+	}
+	if rp.minBackoff <= 0 {
+		rp.minBackoff = defaultMinBackoff
+	}
+	if rp.maxBackoff <= 0 {
+		rp.maxBackoff = defaultMaxBackoff
+	}
+	if rp.maxBackoff < rp.minBackoff {
+		rp.maxBackoff = rp.minBackoff
+	}
+
+	return rp
+}
+
+// backoff returns jittered exponential delay before attempt+1. Retry-After
+// and RateLimit-Reset headers of resp are preferred if present.
+func (rp retryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if d, ok := serverDelay(resp, time.Now()); ok {
+		return d
+	}
+
+	return rp.exponential(attempt)
+}
+
+// errorBackoff is backoff for failed request known only by its error,
+// delay GitLab asked for in error response is preferred.
+func (rp retryPolicy) errorBackoff(attempt int, err error) time.Duration {
+	if ge, ok := gitlab.AsGitlabError(err); ok && ge.RetryAfter > 0 {
+		return ge.RetryAfter
+	}
+
+	return rp.exponential(attempt)
+}
+
+func (rp retryPolicy) exponential(attempt int) time.Duration {
+	d := rp.minBackoff
+	for i := 1; i < attempt && d < rp.maxBackoff; i++ {
+		d *= 2
+	}
+
+	if d > rp.maxBackoff {
+		d = rp.maxBackoff
+	}
+
+	// jitter in [d/2, d)
+	half := int64(d / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// serverDelay reads delay requested by GitLab in Retry-After or RateLimit-Reset
+// headers, delay is capped by maxServerDelay.
+func serverDelay(resp *http.Response, now time.Time) (time.Duration, bool) {
+	d, ok := headerDelay(resp, now)
+	if d > maxServerDelay {
+		d = maxServerDelay
+	}
+
+	return d, ok
+}
+
+func headerDelay(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	if ra := resp.Header.Get("Retry-After"); ra != "" {
+		if sec, err := strconv.Atoi(ra); err == nil && sec >= 0 {
+			return time.Duration(sec) * time.Second, true
+		}
+
+		if t, err := http.ParseTime(ra); err == nil {
+			return nonNegative(t.Sub(now)), true
+		}
+	}
+
+	if resp.Header.Get("RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("RateLimit-Reset"), 10, 64); err == nil {
+			return nonNegative(time.Unix(reset, 0).Sub(now)), true
+		}
+	}
+
+	return 0, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+
+	return d
+}
+
+// isTransientStatus reports statuses worth retrying: rate limit and
+// unavailable GitLab (like during deploy).
+func isTransientStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// isTransientError reports network errors and transient GitLab errors.
+func isTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if ge, ok := gitlab.AsGitlabError(err); ok {
+		return isTransientStatus(ge.StatusCode)
+	}
+
+	var ne net.Error
+	return errors.As(err, &ne) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// isIdempotent reports methods safe to retry. PUT and DELETE are not retried,
+// as some GitLab's PUT requests (like merge) act more than once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	return false
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// retryTransport retries idempotent requests on network errors and transient
+// statuses. Every attempt has own timeout. When GitLab reports exhausted rate limit
+// all following requests wait for its reset.
+type retryTransport struct {
+	next    http.RoundTripper
+	policy  retryPolicy
+	timeout time.Duration
+
+	mu           sync.Mutex
+	blockedUntil time.Time
+}
+
+func newRetryTransport(next http.RoundTripper, policy retryPolicy, timeout time.Duration) *retryTransport {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &retryTransport{
+		next:    next,
+		policy:  policy,
+		timeout: timeout,
+	}
+}
+
+func (rt *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	attempts := rt.policy.maxAttempts
+	if !isIdempotent(req.Method) {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := sleep(ctx, rt.rateLimitDelay())
+		if err != nil {
+			return nil, err
+		}
+
+		areq := req
+		if attempt > 1 && req.GetBody != nil {
+			areq = req.Clone(ctx)
+			areq.Body, err = req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := rt.attempt(areq)
+		rt.updateRateLimit(resp)
+
+		retry := err != nil && isTransientError(err) ||
+			err == nil && isTransientStatus(resp.StatusCode)
+		if !retry || attempt >= attempts || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		d := rt.policy.backoff(attempt, resp)
+
+		log.Ctx(ctx).Debug().
+			Err(err).
+			Int("attempt", attempt).
+			Dur("backoff", d).
+			Stringer("url", req.URL).
+			Msg("retrying request to gitlab")
+
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		err = sleep(ctx, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends request with attempt's timeout, timeout is canceled
+// when response body is closed.
+func (rt *retryTransport) attempt(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), rt.timeout)
+
+	resp, err := rt.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = cancelBody{
+		ReadCloser: resp.Body,
+		cancel:     cancel,
+	}
+
+	return resp, nil
+}
+
+func (rt *retryTransport) rateLimitDelay() time.Duration {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	return nonNegative(time.Until(rt.blockedUntil))
+}
+
+func (rt *retryTransport) updateRateLimit(resp *http.Response) {
+	if resp == nil || resp.Header.Get("RateLimit-Remaining") != "0" {
+		return
+	}
+
+	d, ok := serverDelay(resp, time.Now())
+	if !ok {
+		return
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	rt.blockedUntil = time.Now().Add(d)
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (cb cancelBody) Close() error {
+	defer cb.cancel()
+	return cb.ReadCloser.Close()
+}
@@ -0,0 +1,184 @@
+package impl_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab/impl"
+)
+
+var testRetry = config.Retry{
+	MaxAttempts: 3,
+	MinBackoff:  config.Duration(time.Millisecond),
+	MaxBackoff:  config.Duration(10 * time.Millisecond),
+}
+
+func TestHTTPGitLab_RetryGet(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		switch requests {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(`{"id":1,"username":"john"}`))
+		}
+	}))
+	defer ts.Close()
+
+	gl := impl.NewHTTPGitLab(config.GitLab{URL: ts.URL, Retry: testRetry})
+
+	u, err := gl.CurrentUser(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.ID != 1 || requests != 3 {
+		t.Fatalf("wrong user %+v after %d requests", u, requests)
+	}
+}
+
+func TestHTTPGitLab_RetryGetExhausted(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	gl := impl.NewHTTPGitLab(config.GitLab{URL: ts.URL, Retry: testRetry})
+
+	_, err := gl.CurrentUser(context.Background())
+	if ge, ok := gitlab.AsGitlabError(err); !ok || !ge.IsServerError() {
+		t.Fatal("expected server error, got", err)
+	}
+
+	if requests != testRetry.MaxAttempts {
+		t.Fatalf("expected %d requests, got %d", testRetry.MaxAttempts, requests)
+	}
+}
+
+func TestHTTPGitLab_RetryCreateMR(t *testing.T) {
+	posts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			posts++
+			// MR is created, but proxy failed
+			w.WriteHeader(http.StatusBadGateway)
+		case r.URL.Path == "/api/v4/projects/group/project/merge_requests":
+			if r.URL.Query().Get("source_branch") != "feature" {
+				t.Fatalf("unexpected query: %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`[{"id":10,"iid":1}]`))
+		case r.URL.Path == "/api/v4/projects/group/project/merge_requests/1":
+			_, _ = w.Write([]byte(`{"id":10,"iid":1,"web_url":"url"}`))
+		default:
+			t.Fatalf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer ts.Close()
+
+	gl := impl.NewHTTPGitLab(config.GitLab{URL: ts.URL, Retry: testRetry})
+
+	mr, err := gl.CreateMR(context.Background(), gitlab.CreateMRRequest{
+		Project:      "group/project",
+		SourceBranch: "feature",
+		TargetBranch: "master",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mr.IID != 1 || mr.URL != "url" {
+		t.Fatalf("wrong MR: %+v", mr)
+	}
+
+	if posts != 1 {
+		t.Fatalf("MR should be created once, got %d posts", posts)
+	}
+}
+
+func TestHTTPGitLab_MergeNotRetried(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// MR may be merged, but proxy failed
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}))
+	defer ts.Close()
+
+	gl := impl.NewHTTPGitLab(config.GitLab{URL: ts.URL, Retry: testRetry})
+
+	_, err := gl.MergeMR(context.Background(), gitlab.MergeMRRequest{Project: "group/project", IID: 1})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if requests != 1 {
+		t.Fatalf("merge should not be retried, got %d requests", requests)
+	}
+}
+
+func TestHTTPGitLab_RetryAfterCapped(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	gl := impl.NewHTTPGitLab(config.GitLab{URL: ts.URL, Retry: config.Retry{MaxAttempts: 1}})
+
+	_, err := gl.CurrentUser(context.Background())
+	ge, ok := gitlab.AsGitlabError(err)
+	if !ok || ge.RetryAfter <= 0 || ge.RetryAfter > time.Minute {
+		t.Fatal("expected capped retry delay, got", err, ge.RetryAfter)
+	}
+}
+
+func TestHTTPGitLab_RetryCreateForkMR(t *testing.T) {
+	posts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			posts++
+			w.WriteHeader(http.StatusBadGateway)
+		case r.URL.Path == "/api/v4/projects/me/project":
+			_, _ = w.Write([]byte(`{"id":7}`))
+		case r.URL.Path == "/api/v4/projects/5/merge_requests":
+			// MR of another fork from the same branch name
+			_, _ = w.Write([]byte(`[{"id":11,"iid":2,"source_project_id":8,"source_branch":"feature"},
+				{"id":10,"iid":1,"source_project_id":7,"source_branch":"feature"}]`))
+		case r.URL.Path == "/api/v4/projects/5/merge_requests/1":
+			_, _ = w.Write([]byte(`{"id":10,"iid":1,"web_url":"url"}`))
+		default:
+			t.Fatalf("unexpected request: %s", r.URL)
+		}
+	}))
+	defer ts.Close()
+
+	gl := impl.NewHTTPGitLab(config.GitLab{URL: ts.URL, Retry: testRetry})
+
+	mr, err := gl.CreateMR(context.Background(), gitlab.CreateMRRequest{
+		Project:         "me/project",
+		TargetProjectID: 5,
+		SourceBranch:    "feature",
+		TargetBranch:    "master",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mr.IID != 1 || posts != 1 {
+		t.Fatalf("MR of fork should be found in target project: %+v, %d posts", mr, posts)
+	}
+}
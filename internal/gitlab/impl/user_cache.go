@@ -0,0 +1,96 @@
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+)
+
+// NewUserCache wraps gl, users found by username are cached in file
+// by path, so they are requested from GitLab only once.
+func NewUserCache(gl gitlab.GitLab, host, path string) *UserCache {
+	return &UserCache{
+		GitLab: gl,
+		host:   host,
+		path:   path,
+	}
+}
+
+type UserCache struct {
+	gitlab.GitLab
+
+	host string
+	path string
+
+	mu     sync.Mutex
+	loaded bool
+	users  map[string]gitlab.UserResponse
+}
+
+func (uc *UserCache) UserByUsername(ctx context.Context, username string) (gitlab.UserResponse, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if !uc.loaded {
+		uc.load(ctx)
+	}
+
+	key := uc.host + "/" + strings.ToLower(strings.TrimPrefix(username, "@"))
+	if u, ok := uc.users[key]; ok {
+		return u, nil
+	}
+
+	u, err := uc.GitLab.UserByUsername(ctx, username)
+	if err != nil || u.ID == 0 {
+		return u, err
+	}
+
+	uc.users[key] = u
+	uc.save(ctx)
+
+	return u, nil
+}
+
+// load reads cache file, cache is optional so errors are only logged.
+func (uc *UserCache) load(ctx context.Context) {
+	uc.loaded = true
+	uc.users = map[string]gitlab.UserResponse{}
+
+	data, err := ioutil.ReadFile(uc.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Ctx(ctx).Debug().Err(err).Msg("can not read users cache")
+		}
+		return
+	}
+
+	err = json.Unmarshal(data, &uc.users)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("can not decode users cache")
+		uc.users = map[string]gitlab.UserResponse{}
+	}
+}
+
+func (uc *UserCache) save(ctx context.Context) {
+	data, err := json.Marshal(uc.users)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("can not encode users cache")
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(uc.path), 0o700)
+	if err == nil {
+		err = ioutil.WriteFile(uc.path, data, 0o600)
+	}
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("can not write users cache")
+	}
+}
@@ -0,0 +1,47 @@
+package impl_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab/impl"
+)
+
+func TestUserCache_UserByUsername(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.URL.Path != "/api/v4/users" || r.URL.Query().Get("username") != "john" {
+			t.Fatalf("unexpected request: %s", r.URL)
+		}
+
+		_, _ = w.Write([]byte(`[{"id":42,"username":"john"}]`))
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "users.json")
+
+	for i := 0; i < 2; i++ {
+		uc := impl.NewUserCache(impl.NewHTTPGitLab(config.GitLab{Token: "token", URL: ts.URL}), ts.URL, path)
+
+		for _, username := range []string{"john", "@John"} {
+			u, err := uc.UserByUsername(context.Background(), username)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if u.ID != 42 {
+				t.Fatalf("wrong user: %+v", u)
+			}
+		}
+	}
+
+	if requests != 1 {
+		t.Fatalf("expected 1 request to gitlab, got %d", requests)
+	}
+}
@@ -0,0 +1,227 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+)
+
+var ErrBackportFailed = errors.New("backport failed")
+
+// Statuses of backport MRs failed to be created.
+const (
+	MRStatusConflict = "conflict"
+	MRStatusFailed   = "failed"
+)
+
+// Default templates of backport MRs, Title and Description are
+// title and description of original MR.
+const (
+	DefaultBackportTitle       = "[Backport {{.BackportVersion}}] {{.Title}}"
+	DefaultBackportDescription = "Backport of {{.OriginalMergeRequestURL}} to {{.TargetBranchName}}.\n\n{{.Description}}"
+)
+
+const backportBranchPrefix = "backport/"
+
+type BackportParams struct {
+	// Targets are branches MR is backported to.
+	Targets []string
+	// MR is IID of MR to backport, commits of current branch's MR are backported by default.
+	MR int64
+	// TargetBranch is used to choose MR if there are several MRs for current branch.
+	TargetBranch string
+	// TitleTemplate and DescriptionTemplate are templates of backport MRs,
+	// Default* templates are used if empty.
+	TitleTemplate       string
+	DescriptionTemplate string
+	// CreateMR are params of backport MRs, their branches, templates and checks are replaced.
+	CreateMR CreateMRParams
+}
+
+// BackportMR is MR of backport to one target branch.
+type BackportMR struct {
+	TargetBranch string `json:"target_branch"`
+	Branch       string `json:"branch"`
+	IID          int64  `json:"iid"`
+	URL          string `json:"url"`
+	// Status is one of MRStatus* constants.
+	Status string `json:"status"`
+	// Error tells why backport failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Backport cherry-picks commits of MR onto every target branch and creates MRs
+// of backport branches. Failed targets do not stop others, they are reported
+// in result and in error wrapping ErrBackportFailed.
+func (c *Core) Backport(ctx context.Context, params BackportParams) ([]BackportMR, error) {
+	if len(params.Targets) == 0 {
+		return nil, errors.New("backport target branch is required")
+	}
+
+	ri, err := c.repoInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	omr, commits, err := c.backportSource(ctx, ri, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("no commits to backport in !%d", omr.IID)
+	}
+
+	var (
+		bmrs   = make([]BackportMR, 0, len(params.Targets))
+		failed []string
+		// nestedErr is an error happened after MR creation, like failed notification
+		nestedErr error
+	)
+
+	for _, t := range params.Targets {
+		bmr, err := c.backportTo(ctx, omr, commits, t, params)
+		if bmr.Error != "" {
+			failed = append(failed, t)
+		} else if err != nil && nestedErr == nil {
+			nestedErr = err
+		}
+
+		bmrs = append(bmrs, bmr)
+	}
+
+	if len(failed) > 0 {
+		return bmrs, fmt.Errorf("%w: %s", ErrBackportFailed, strings.Join(failed, ", "))
+	}
+
+	return bmrs, nestedErr
+}
+
+// backportSource returns MR to backport and its commits, oldest first.
+func (c *Core) backportSource(
+	ctx context.Context,
+	ri repoInfo,
+	params BackportParams,
+) (gitlab.MergeRequest, []string, error) {
+	if params.MR == 0 {
+		mr, err := c.branchMR(ctx, ri, params.TargetBranch)
+		if err != nil {
+			return mr, nil, err
+		}
+
+		cs, err := c.git.BranchCommits(ri.branch, mr.TargetBranch)
+		if err != nil {
+			return mr, nil, err
+		}
+
+		shas := make([]string, len(cs))
+		for i, cm := range cs {
+			shas[len(cs)-1-i] = cm.SHA
+		}
+
+		return mr, shas, nil
+	}
+
+	mr, err := c.gitLab.GetMR(ctx, ri.project, params.MR)
+	if err != nil {
+		return mr, nil, err
+	}
+
+	cs, err := c.gitLab.MRCommits(ctx, ri.project, params.MR)
+	if err != nil {
+		return mr, nil, err
+	}
+
+	// source branch of merged MR may be removed
+	err = c.git.FetchMR(ctx, params.MR)
+	if err != nil {
+		return mr, nil, err
+	}
+
+	shas := make([]string, len(cs))
+	for i, cm := range cs {
+		shas[len(cs)-1-i] = cm.ID
+	}
+
+	return mr, shas, nil
+}
+
+// backportTo cherry-picks commits onto target and creates MR. Error of MR
+// creation is returned if MR is created anyway.
+func (c *Core) backportTo(
+	ctx context.Context,
+	omr gitlab.MergeRequest,
+	commits []string,
+	target string,
+	params BackportParams,
+) (BackportMR, error) {
+	bmr := BackportMR{
+		TargetBranch: target,
+		Branch:       backportBranchPrefix + target + "/" + omr.SourceBranch,
+	}
+
+	err := c.git.CherryPick(ctx, bmr.Branch, target, commits)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("target", target).Msg("can not backport")
+
+		bmr.Status = MRStatusFailed
+		if errors.Is(err, ErrConflict) {
+			bmr.Status = MRStatusConflict
+		}
+		bmr.Error = err.Error()
+
+		return bmr, err
+	}
+
+	mp := params.CreateMR
+	mp.SourceBranch = bmr.Branch
+	mp.TargetBranch = target
+	mp.TitleTemplate = params.TitleTemplate
+	mp.DescriptionTemplate = params.DescriptionTemplate
+	mp.Template = ""
+	mp.ProjectTemplates = nil
+	mp.Push = true
+	mp.Checks = ChecksParams{}
+	// issues belong to original MR, they are not transitioned or closed again
+	mp.Issue = IssueParams{}
+	mp.Jira = JiraParams{}
+
+	if mp.TitleTemplate == "" {
+		mp.TitleTemplate = DefaultBackportTitle
+	}
+	if mp.DescriptionTemplate == "" {
+		mp.DescriptionTemplate = DefaultBackportDescription
+	}
+
+	// variables of backport branch are taken from original branch
+	mp.TextArgs = map[string]interface{}{}
+	for k, v := range branchVars(mp.BranchRegexp, omr.SourceBranch) {
+		mp.TextArgs[k] = v
+	}
+
+	mp.TextArgs[TmpVarTitle] = omr.Title
+	mp.TextArgs[TmpVarDescription] = omr.Description
+	mp.TextArgs[TmpVarOriginalMRURL] = omr.URL
+	mp.TextArgs[TmpVarBackportVersion] = path.Base(target)
+
+	mr, err := c.CreateMR(ctx, mp)
+	// MR without URL is not created
+	if err != nil && mr.URL == "" {
+		bmr.Status = MRStatusFailed
+		bmr.Error = err.Error()
+
+		return bmr, err
+	}
+
+	bmr.IID = mr.IID
+	bmr.URL = mr.URL
+	bmr.Status = mr.Status
+
+	return bmr, err
+}
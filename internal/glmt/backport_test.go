@@ -0,0 +1,124 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	hooksi "gitlab.com/gitlab-merge-tool/glmt/internal/hooks/impl"
+)
+
+// backportGit records fetched MRs and cherry-picked commits.
+type backportGit struct {
+	gitStub
+	// picked are commits cherry-picked by branch.
+	picked map[string][]string
+	// conflicts are base branches CherryPick conflicts with.
+	conflicts  map[string]bool
+	fetchedMRs []int64
+}
+
+func (bg *backportGit) CherryPick(ctx context.Context, branch, base string, commits []string) error {
+	if bg.conflicts[base] {
+		return fmt.Errorf("%w in file.go", ErrConflict)
+	}
+
+	if bg.picked == nil {
+		bg.picked = map[string][]string{}
+	}
+
+	bg.picked[branch] = commits
+	return nil
+}
+
+func (bg *backportGit) FetchMR(ctx context.Context, iid int64) error {
+	bg.fetchedMRs = append(bg.fetchedMRs, iid)
+	return nil
+}
+
+// backportGitLab returns commits of backported MR.
+type backportGitLab struct {
+	gitlabStub
+	commits []gitlab.Commit
+}
+
+func (bg *backportGitLab) MRCommits(ctx context.Context, project string, iid int64) ([]gitlab.Commit, error) {
+	bg.f("MRCommits", iid)
+	return bg.commits, nil
+}
+
+func TestBackport(t *testing.T) {
+	var created []gitlab.CreateMRRequest
+
+	gls := &backportGitLab{
+		gitlabStub: gitlabStub{
+			f: func(method string, arg interface{}) {
+				if method == "CreateMR" {
+					created = append(created, arg.(gitlab.CreateMRRequest))
+				}
+			},
+			mr: gitlab.MergeRequest{
+				IID:          7,
+				Title:        "Fix crash",
+				Description:  "Crash is fixed",
+				SourceBranch: "fix-crash",
+				URL:          "https://gitlab.com/hummerd/client_golang/-/merge_requests/7",
+			},
+		},
+		commits: []gitlab.Commit{{ID: "c2"}, {ID: "c1"}},
+	}
+
+	gs := &backportGit{
+		gitStub:   gitStub{r: testRemote, b: "master"},
+		conflicts: map[string]bool{"release/1.4": true},
+	}
+
+	c := Core{
+		git:    gs,
+		gitLab: gls,
+		hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+	}
+
+	bmrs, err := c.Backport(context.Background(), BackportParams{
+		Targets: []string{"release/1.4", "release/1.5"},
+		MR:      7,
+	})
+	if !errors.Is(err, ErrBackportFailed) || !strings.Contains(err.Error(), "release/1.4") {
+		t.Fatal("exp failed backport to release/1.4, got", err)
+	}
+
+	if !reflect.DeepEqual(gs.fetchedMRs, []int64{7}) {
+		t.Fatal("MR should be fetched", gs.fetchedMRs)
+	}
+
+	if len(bmrs) != 2 || bmrs[0].Status != MRStatusConflict || bmrs[1].Status != MRStatusCreated {
+		t.Fatal("wrong backport MRs", bmrs)
+	}
+
+	exp := []string{"c1", "c2"}
+	if !reflect.DeepEqual(gs.picked["backport/release/1.5/fix-crash"], exp) {
+		t.Fatalf("exp picked: %v, got: %v", exp, gs.picked)
+	}
+
+	if len(created) != 1 {
+		t.Fatal("exp single MR, got", created)
+	}
+
+	req := created[0]
+	if req.SourceBranch != "backport/release/1.5/fix-crash" || req.TargetBranch != "release/1.5" {
+		t.Fatal("wrong branches of backport MR", req)
+	}
+
+	if req.Title != "[Backport 1.5] Fix crash" {
+		t.Fatal("wrong title", req.Title)
+	}
+
+	if !strings.Contains(req.Description, gls.mr.URL) || !strings.Contains(req.Description, "Crash is fixed") {
+		t.Fatal("description should link original MR", req.Description)
+	}
+}
@@ -0,0 +1,249 @@
+package glmt
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/templating"
+)
+
+// DefaultChangelogTemplate renders changelog as markdown list of MRs by groups.
+const DefaultChangelogTemplate = `{{range .Groups}}### {{.Title}}
+
+{{range .MergeRequests}}* {{.Title}} ([!{{.IID}}]({{.URL}})) @{{.Author}}
+{{end}}
+{{end}}`
+
+// defaultOtherGroup is a title of group of MRs matching no group rule.
+const defaultOtherGroup = "Other"
+
+// mergeTimeSlack extends search of merged MRs before the oldest commit of range,
+// as commit dates and merge dates of MRs may differ a bit.
+const mergeTimeSlack = 24 * time.Hour
+
+type ChangelogParams struct {
+	// From and To are git revisions, changelog lists MRs merged in commits
+	// reachable from To, but not from From. Empty From means all commits.
+	From string
+	To   string
+	// TargetBranch limits MRs to those merged into branch, empty means any branch.
+	TargetBranch string
+	BranchRegexp *regexp.Regexp
+	// GroupVar is a name of branch_regexp group MRs are grouped by.
+	GroupVar string
+	// Groups are rules of groups in order of output, MR goes to the first
+	// matching group. Without groups MRs are grouped by values of GroupVar.
+	Groups []ChangelogGroupRule
+	// OtherGroup is a title of group of MRs matching no rule, default is "Other".
+	OtherGroup string
+}
+
+// ChangelogGroupRule matches MRs with any of Labels or with value of
+// GroupVar from Values.
+type ChangelogGroupRule struct {
+	Title  string
+	Labels []string
+	Values []string
+}
+
+type Changelog struct {
+	From   string           `json:"from"`
+	To     string           `json:"to"`
+	Groups []ChangelogGroup `json:"groups"`
+}
+
+type ChangelogGroup struct {
+	Title         string        `json:"title"`
+	MergeRequests []ChangelogMR `json:"merge_requests"`
+}
+
+type ChangelogMR struct {
+	IID          int64     `json:"iid"`
+	Title        string    `json:"title"`
+	URL          string    `json:"url"`
+	Author       string    `json:"author"`
+	Labels       []string  `json:"labels"`
+	SourceBranch string    `json:"source_branch"`
+	TargetBranch string    `json:"target_branch"`
+	MergedAt     time.Time `json:"merged_at"`
+	// Vars are values of branch_regexp groups of source branch.
+	Vars map[string]string `json:"vars"`
+}
+
+// Changelog collects MRs merged between two revisions. MRs are searched in GitLab
+// by merge date and matched to commits of range by merge, squash or head commits.
+func (c *Core) Changelog(ctx context.Context, params ChangelogParams) (Changelog, error) {
+	cl := Changelog{From: params.From, To: params.To}
+
+	ri, err := c.repoInfo()
+	if err != nil {
+		return cl, err
+	}
+
+	cs, err := c.git.RangeCommits(params.From, params.To)
+	if err != nil {
+		return cl, err
+	}
+
+	if len(cs) == 0 {
+		return cl, nil
+	}
+
+	shas := make(map[string]bool, len(cs))
+	since := cs[0].Date
+	for _, cm := range cs {
+		shas[cm.SHA] = true
+		if cm.Date.Before(since) {
+			since = cm.Date
+		}
+	}
+
+	mrs, err := c.gitLab.ListMRs(ctx, gitlab.ListMRsRequest{
+		Project:      ri.project,
+		State:        "merged",
+		TargetBranch: params.TargetBranch,
+		UpdatedAfter: since.Add(-mergeTimeSlack),
+	})
+	if err != nil {
+		return cl, fmt.Errorf("can not list merged MRs: %w", err)
+	}
+
+	var cmrs []ChangelogMR
+	for _, mr := range mrs {
+		if !shas[mr.MergeCommitSHA] && !shas[mr.SquashCommitSHA] && !shas[mr.SHA] {
+			continue
+		}
+
+		cmr := ChangelogMR{
+			IID:          mr.IID,
+			Title:        mr.Title,
+			URL:          mr.URL,
+			Author:       mr.Author.Username,
+			Labels:       mr.Labels,
+			SourceBranch: mr.SourceBranch,
+			TargetBranch: mr.TargetBranch,
+			MergedAt:     mr.UpdatedAt,
+			Vars:         branchVars(params.BranchRegexp, mr.SourceBranch),
+		}
+		if mr.MergedAt != nil {
+			cmr.MergedAt = *mr.MergedAt
+		}
+
+		cmrs = append(cmrs, cmr)
+	}
+
+	sort.SliceStable(cmrs, func(i, j int) bool {
+		return cmrs[i].MergedAt.Before(cmrs[j].MergedAt)
+	})
+
+	cl.Groups = groupChangelog(cmrs, params)
+
+	return cl, nil
+}
+
+// PostRelease sets notes as description of tag's release, release is created
+// if tag has none. URL of release is returned.
+func (c *Core) PostRelease(ctx context.Context, tag, notes string) (string, error) {
+	ri, err := c.repoInfo()
+	if err != nil {
+		return "", err
+	}
+
+	req := gitlab.ReleaseRequest{
+		Project:     ri.project,
+		TagName:     tag,
+		Name:        tag,
+		Description: notes,
+	}
+
+	r, err := c.gitLab.CreateRelease(ctx, req)
+	if ge, ok := gitlab.AsGitlabError(err); ok && ge.IsConflict() {
+		// name of existing release is kept
+		req.Name = ""
+		r, err = c.gitLab.UpdateRelease(ctx, req)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return r.Links.Self, nil
+}
+
+// RenderChangelog renders changelog with template, empty template means
+// DefaultChangelogTemplate.
+func RenderChangelog(cl Changelog, tmpl string) (string, error) {
+	if tmpl == "" {
+		tmpl = DefaultChangelogTemplate
+	}
+
+	return templating.Render("changelog", tmpl, cl)
+}
+
+// groupChangelog groups MRs by rules, empty groups are skipped and
+// group of other MRs goes last.
+func groupChangelog(cmrs []ChangelogMR, params ChangelogParams) []ChangelogGroup {
+	other := params.OtherGroup
+	if other == "" {
+		other = defaultOtherGroup
+	}
+
+	var (
+		titles []string
+		groups = map[string][]ChangelogMR{}
+	)
+
+	for _, r := range params.Groups {
+		titles = append(titles, r.Title)
+	}
+
+	for _, cmr := range cmrs {
+		t := changelogGroup(cmr, params)
+		if t == "" {
+			t = other
+		}
+
+		if _, ok := groups[t]; !ok && len(params.Groups) == 0 && t != other {
+			titles = append(titles, t)
+		}
+
+		groups[t] = append(groups[t], cmr)
+	}
+
+	titles = append(titles, other)
+
+	var cgs []ChangelogGroup
+	for _, t := range titles {
+		if len(groups[t]) > 0 {
+			cgs = append(cgs, ChangelogGroup{Title: t, MergeRequests: groups[t]})
+			delete(groups, t)
+		}
+	}
+
+	return cgs
+}
+
+// changelogGroup returns title of MR's group, empty if MR matches no group.
+func changelogGroup(cmr ChangelogMR, params ChangelogParams) string {
+	v := cmr.Vars[params.GroupVar]
+	if len(params.Groups) == 0 {
+		return v
+	}
+
+	for _, r := range params.Groups {
+		for _, l := range r.Labels {
+			if containsFold(cmr.Labels, l) {
+				return r.Title
+			}
+		}
+
+		if params.GroupVar != "" && v != "" && containsFold(r.Values, v) {
+			return r.Title
+		}
+	}
+
+	return ""
+}
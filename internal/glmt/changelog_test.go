@@ -0,0 +1,139 @@
+package glmt
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+)
+
+func TestChangelog(t *testing.T) {
+	now := time.Now()
+
+	var listReq gitlab.ListMRsRequest
+
+	gls := &gitlabStub{
+		f: func(method string, arg interface{}) {},
+		listMRs: func(req gitlab.ListMRsRequest) []gitlab.MergeRequest {
+			listReq = req
+			return []gitlab.MergeRequest{
+				{IID: 1, Title: "Old", SourceBranch: "feat/old", MergeCommitSHA: "old"},
+				{IID: 2, Title: "Bug", SourceBranch: "fix/bug", SquashCommitSHA: "s2", Labels: []string{"bug"}},
+				{IID: 3, Title: "Feature", SourceBranch: "feat/new", MergeCommitSHA: "m3"},
+				{IID: 4, Title: "Docs", SourceBranch: "docs/readme", SHA: "h4"},
+			}
+		},
+	}
+
+	c := Core{
+		git: &gitStub{
+			r: testRemote,
+			b: "master",
+			commits: []Commit{
+				{SHA: "h4", Date: now},
+				{SHA: "m3", Date: now.Add(-time.Hour)},
+				{SHA: "s2", Date: now.Add(-2 * time.Hour)},
+			},
+		},
+		gitLab: gls,
+	}
+
+	cl, err := c.Changelog(context.Background(), ChangelogParams{
+		From:         "v1.0.0",
+		To:           "HEAD",
+		BranchRegexp: regexp.MustCompile(`(?P<TaskType>\w+)/.+`),
+		GroupVar:     "TaskType",
+		Groups: []ChangelogGroupRule{
+			{Title: "Features", Values: []string{"feat"}},
+			{Title: "Fixes", Labels: []string{"Bug"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if listReq.State != "merged" || !listReq.UpdatedAfter.Before(now.Add(-2*time.Hour)) {
+		t.Fatal("wrong list request", listReq)
+	}
+
+	exp := []struct {
+		title string
+		iid   int64
+	}{
+		{"Features", 3},
+		{"Fixes", 2},
+		{"Other", 4},
+	}
+
+	if len(cl.Groups) != len(exp) {
+		t.Fatal("wrong groups", cl.Groups)
+	}
+
+	for i, e := range exp {
+		g := cl.Groups[i]
+		if g.Title != e.title || len(g.MergeRequests) != 1 || g.MergeRequests[0].IID != e.iid {
+			t.Errorf("exp group %s with !%d, got %+v", e.title, e.iid, g)
+		}
+	}
+
+	notes, err := RenderChangelog(cl, `{{range .Groups}}{{upper .Title}}:{{range .MergeRequests}} !{{.IID}}{{end}};{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if notes != "FEATURES: !3;FIXES: !2;OTHER: !4;" {
+		t.Fatal("wrong notes", notes)
+	}
+}
+
+// releaseGitLab has releases of some tags.
+type releaseGitLab struct {
+	gitlabStub
+	// releases are tags with existing releases.
+	releases map[string]bool
+}
+
+func (rg *releaseGitLab) CreateRelease(ctx context.Context, req gitlab.ReleaseRequest) (gitlab.Release, error) {
+	rg.f("CreateRelease", req)
+	if rg.releases[req.TagName] {
+		return gitlab.Release{}, gitlab.GitlabError{StatusCode: http.StatusConflict, Message: "Release already exists"}
+	}
+
+	return gitlab.Release{TagName: req.TagName, Links: gitlab.ReleaseLinks{Self: "created"}}, nil
+}
+
+func (rg *releaseGitLab) UpdateRelease(ctx context.Context, req gitlab.ReleaseRequest) (gitlab.Release, error) {
+	rg.f("UpdateRelease", req)
+	return gitlab.Release{TagName: req.TagName, Links: gitlab.ReleaseLinks{Self: "updated"}}, nil
+}
+
+func TestPostRelease(t *testing.T) {
+	var calls []string
+
+	c := Core{
+		git: &gitStub{
+			r: testRemote,
+			b: "master",
+		},
+		gitLab: &releaseGitLab{
+			gitlabStub: gitlabStub{
+				f: func(method string, arg interface{}) {
+					calls = append(calls, method)
+				},
+			},
+			releases: map[string]bool{"v1.1.0": true},
+		},
+	}
+
+	u, err := c.PostRelease(context.Background(), "v1.1.0", "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u != "updated" || len(calls) != 2 || calls[1] != "UpdateRelease" {
+		t.Fatal("existing release should be updated", u, calls)
+	}
+}
@@ -0,0 +1,85 @@
+package glmt
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var ErrChecksFailed = errors.New("checks failed")
+
+// fixupRegExp matches subjects of commits that should be squashed or finished before MR.
+var fixupRegExp = regexp.MustCompile(`(?i)^\s*(fixup!|squash!|amend!|wip\b|\[wip\])`)
+
+// ChecksParams switches local repository checks made before MR creation.
+type ChecksParams struct {
+	// CleanWorktree checks there are no modified or staged files.
+	CleanWorktree bool
+	// UpToDate checks branch points to the same commit as its remote branch.
+	UpToDate bool
+	// BehindTarget checks branch is behind target branch
+	// by no more than MaxBehindTarget commits.
+	BehindTarget    bool
+	MaxBehindTarget int
+	// NoFixupCommits checks there are no fixup!, squash! and WIP commits in branch.
+	NoFixupCommits bool
+}
+
+// runChecks checks local repository, all failed checks are reported in a single error.
+func (c *Core) runChecks(ri repoInfo, target string, params ChecksParams) error {
+	var problems []string
+
+	if params.CleanWorktree {
+		files, err := c.git.DirtyFiles()
+		if err != nil {
+			return err
+		}
+
+		if len(files) > 0 {
+			problems = append(problems, "worktree has uncommitted changes: "+strings.Join(files, ", "))
+		}
+	}
+
+	if params.UpToDate {
+		ok, err := c.git.InSync(ri.remoteName, ri.branch)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s differs from %s/%s", ri.branch, ri.remoteName, ri.branch))
+		}
+	}
+
+	if params.BehindTarget {
+		n, err := c.git.Behind(ri.branch, target)
+		if err != nil {
+			return err
+		}
+
+		if n > params.MaxBehindTarget {
+			problems = append(problems, fmt.Sprintf("%s is behind %s by %d commit(s), max is %d",
+				ri.branch, target, n, params.MaxBehindTarget))
+		}
+	}
+
+	if params.NoFixupCommits {
+		cs, err := c.git.BranchCommits(ri.branch, target)
+		if err != nil {
+			return err
+		}
+
+		for _, cm := range cs {
+			if fixupRegExp.MatchString(cm.Subject) {
+				problems = append(problems, "unfinished commit: "+cm.Subject)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w:\n  - %s", ErrChecksFailed, strings.Join(problems, "\n  - "))
+	}
+
+	return nil
+}
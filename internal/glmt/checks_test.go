@@ -0,0 +1,151 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	hooksi "gitlab.com/gitlab-merge-tool/glmt/internal/hooks/impl"
+)
+
+// checksGit is a repository failing checks.
+type checksGit struct {
+	gitStub
+	dirty     []string
+	outOfSync bool
+	behind    int
+}
+
+func (cg *checksGit) DirtyFiles() ([]string, error) {
+	return cg.dirty, nil
+}
+
+func (cg *checksGit) InSync(remote, branch string) (bool, error) {
+	return !cg.outOfSync, nil
+}
+
+func (cg *checksGit) Behind(branch, target string) (int, error) {
+	return cg.behind, nil
+}
+
+func TestCreateMR_Checks(t *testing.T) {
+	gs := &checksGit{
+		gitStub: gitStub{
+			r: testRemote,
+			b: "feature",
+			commits: []Commit{
+				{Subject: "fixup! add feature"},
+				{Subject: "WIP tests"},
+				{Subject: "add feature", Body: "details"},
+			},
+		},
+		dirty:     []string{"main.go"},
+		outOfSync: true,
+		behind:    5,
+	}
+
+	called := false
+	gls := &gitlabStub{
+		f: func(method string, arg interface{}) {
+			called = true
+		},
+	}
+
+	c := Core{
+		git:    gs,
+		gitLab: gls,
+		hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+	}
+
+	cp := CreateMRParams{
+		TargetBranch: "master",
+		Checks: ChecksParams{
+			CleanWorktree:   true,
+			UpToDate:        true,
+			BehindTarget:    true,
+			MaxBehindTarget: 3,
+			NoFixupCommits:  true,
+		},
+	}
+
+	_, err := c.CreateMR(context.Background(), cp)
+	if !errors.Is(err, ErrChecksFailed) {
+		t.Fatal("expected ErrChecksFailed, got", err)
+	}
+
+	for _, p := range []string{
+		"main.go",
+		"feature differs from origin/feature",
+		"behind master by 5 commit(s)",
+		"unfinished commit: fixup! add feature",
+		"unfinished commit: WIP tests",
+	} {
+		if !strings.Contains(err.Error(), p) {
+			t.Fatalf("expected %q in report: %s", p, err)
+		}
+	}
+
+	if strings.Contains(err.Error(), "details") {
+		t.Fatal("only commit subjects should be reported:", err)
+	}
+
+	if called {
+		t.Fatal("GitLab should not be called when checks fail")
+	}
+
+	cp.Checks.MaxBehindTarget = 5
+	cp.Checks.CleanWorktree = false
+	cp.Checks.UpToDate = false
+	cp.Checks.NoFixupCommits = false
+	_, err = c.CreateMR(context.Background(), cp)
+	if err != nil {
+		t.Fatal("error creating MR", err)
+	}
+}
+
+// checkedPushGit records push of branch failing checks.
+type checkedPushGit struct {
+	checksGit
+	pushed bool
+}
+
+func (pg *checkedPushGit) Push(ctx context.Context, remote, branch string) error {
+	pg.pushed = true
+	return nil
+}
+
+func TestCreateMR_ChecksBeforePush(t *testing.T) {
+	gs := &checkedPushGit{
+		checksGit: checksGit{
+			gitStub:   gitStub{r: testRemote, b: "feature"},
+			dirty:     []string{"main.go"},
+			outOfSync: true,
+		},
+	}
+
+	c := Core{
+		git:    gs,
+		gitLab: &gitlabStub{f: func(string, interface{}) {}},
+		hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+	}
+
+	cp := CreateMRParams{
+		TargetBranch: "master",
+		Push:         true,
+		Checks:       ChecksParams{CleanWorktree: true, UpToDate: true},
+	}
+
+	_, err := c.CreateMR(context.Background(), cp)
+	if !errors.Is(err, ErrChecksFailed) || gs.pushed {
+		t.Fatal("branch failing checks should not be pushed", err)
+	}
+
+	// branch differing from remote is going to be pushed
+	gs.dirty = nil
+	_, err = c.CreateMR(context.Background(), cp)
+	if err != nil || !gs.pushed {
+		t.Fatal("branch should be pushed", err)
+	}
+}
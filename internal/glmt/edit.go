@@ -0,0 +1,208 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/team"
+)
+
+var ErrEditAborted = errors.New("merge request aborted")
+
+// editScissors separates edited MR from hints, like in git commit --cleanup=scissors.
+// Description is markdown, so lines starting with "#" can not be ignored.
+const editScissors = "# ------------------------ >8 ------------------------"
+
+const editHints = editScissors + `
+# Do not modify or remove the line above.
+# Everything below it will be ignored.
+#
+# Fields at the top can be changed, removed Labels or Reviewers are left empty,
+# Target is required.
+# First line after fields is MR's title, the rest is MR's description.
+# Remove everything to abort MR.
+`
+
+// Fields of edited MR.
+const (
+	editFieldTarget    = "Target"
+	editFieldLabels    = "Labels"
+	editFieldReviewers = "Reviewers"
+)
+
+var editFieldRegExp = regexp.MustCompile(`^(\w+):\s*(.*)$`)
+
+// mrEdit is a part of MR user can change in editor.
+type mrEdit struct {
+	TargetBranch string
+	Title        string
+	Description  string
+	Labels       []string
+	// Reviewers are usernames of mentioned members.
+	Reviewers []string
+}
+
+// editMR opens MR in editor and applies user's changes to req. Mentioned members
+// changed by user are returned.
+func (c *Core) editMR(
+	ctx context.Context,
+	mode string,
+	ms []*team.Member,
+	req *gitlab.CreateMRRequest,
+) ([]*team.Member, error) {
+	if c.editor == nil {
+		return nil, errors.New("editor is not configured")
+	}
+
+	me := mrEdit{
+		TargetBranch: req.TargetBranch,
+		Title:        req.Title,
+		Description:  req.Description,
+		Labels:       splitList(req.Labels),
+	}
+
+	for _, m := range ms {
+		me.Reviewers = append(me.Reviewers, "@"+m.Username)
+	}
+
+	text, err := c.editor.Edit(ctx, formatMREdit(me))
+	if err != nil {
+		return nil, fmt.Errorf("can not edit merge request: %w", err)
+	}
+
+	me, err = parseMREdit(text)
+	if err != nil {
+		return nil, err
+	}
+
+	req.TargetBranch = me.TargetBranch
+	req.Title = me.Title
+	req.Description = me.Description
+	req.Labels = strings.Join(me.Labels, ",")
+
+	ms = editedMembers(ms, me.Reviewers)
+	req.ReviewerIDs, req.AssigneeIDs, err = c.mentionIDs(ctx, mode, req.AssigneeID, ms)
+	if err != nil {
+		return nil, err
+	}
+
+	return ms, nil
+}
+
+func formatMREdit(me mrEdit) string {
+	var sb strings.Builder
+
+	sb.WriteString(editFieldTarget + ": " + me.TargetBranch + "\n")
+	sb.WriteString(editFieldLabels + ": " + strings.Join(me.Labels, ", ") + "\n")
+	sb.WriteString(editFieldReviewers + ": " + strings.Join(me.Reviewers, ", ") + "\n")
+	sb.WriteString("\n" + me.Title + "\n")
+
+	if me.Description != "" {
+		sb.WriteString("\n" + me.Description + "\n")
+	}
+
+	sb.WriteString("\n" + editHints)
+
+	return sb.String()
+}
+
+// parseMREdit parses text written by formatMREdit and edited by user.
+func parseMREdit(text string) (mrEdit, error) {
+	var me mrEdit
+
+	if i := strings.Index(text, "\n"+editScissors); i >= 0 {
+		text = text[:i]
+	} else if strings.HasPrefix(text, editScissors) {
+		text = ""
+	}
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return me, fmt.Errorf("%w: empty file", ErrEditAborted)
+	}
+
+	lines := strings.Split(text, "\n")
+
+	// fields go first till title
+	for len(lines) > 0 && parseEditField(&me, lines[0]) {
+		lines = lines[1:]
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+
+	if len(lines) == 0 {
+		return me, fmt.Errorf("%w: empty title", ErrEditAborted)
+	}
+
+	me.Title = strings.TrimSpace(lines[0])
+	me.Description = strings.TrimSpace(strings.Join(lines[1:], "\n"))
+
+	if me.TargetBranch == "" {
+		return me, errors.New("target branch is required")
+	}
+
+	return me, nil
+}
+
+// parseEditField sets field of me from line, false is returned if line is not a field.
+func parseEditField(me *mrEdit, line string) bool {
+	m := editFieldRegExp.FindStringSubmatch(line)
+	if m == nil {
+		return false
+	}
+
+	v := strings.TrimSpace(m[2])
+	switch {
+	case strings.EqualFold(m[1], editFieldTarget):
+		me.TargetBranch = v
+	case strings.EqualFold(m[1], editFieldLabels):
+		me.Labels = splitList(v)
+	case strings.EqualFold(m[1], editFieldReviewers):
+		me.Reviewers = splitList(v)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// editedMembers returns members by usernames, known members keep
+// their names for notifications.
+func editedMembers(ms []*team.Member, usernames []string) []*team.Member {
+	edited := make([]*team.Member, 0, len(usernames))
+
+	for _, u := range usernames {
+		u = strings.TrimPrefix(u, "@")
+
+		m := &team.Member{Username: u, IsActive: true}
+		for _, em := range ms {
+			if strings.EqualFold(em.Username, u) {
+				m = em
+				break
+			}
+		}
+
+		edited = append(edited, m)
+	}
+
+	return edited
+}
+
+// splitList splits comma separated list skipping empty items.
+func splitList(list string) []string {
+	var items []string
+	for _, i := range strings.Split(list, ",") {
+		i = strings.TrimSpace(i)
+		if i != "" {
+			items = append(items, i)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,174 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	hooksi "gitlab.com/gitlab-merge-tool/glmt/internal/hooks/impl"
+)
+
+type editorStub struct {
+	// text is a text passed to editor.
+	text string
+	edit func(text string) string
+}
+
+func (es *editorStub) Edit(ctx context.Context, text string) (string, error) {
+	es.text = text
+	return es.edit(text), nil
+}
+
+func TestCreateMR_Edit(t *testing.T) {
+	var req gitlab.CreateMRRequest
+	gls := &gitlabStub{
+		f: func(method string, arg interface{}) {
+			if method == "CreateMR" {
+				req = arg.(gitlab.CreateMRRequest)
+			}
+		},
+		users: map[string]int{"jane": 12},
+	}
+
+	es := &editorStub{
+		edit: func(text string) string {
+			return "Target: develop\n" +
+				"Labels: bug, backend\n" +
+				"Reviewers: @jane\n" +
+				"\n" +
+				"Fix crash\n" +
+				"\n" +
+				"## Details\n" +
+				"Crash on start\n" +
+				text[strings.Index(text, editScissors):]
+		},
+	}
+
+	c := Core{
+		git: &gitStub{
+			r: testRemote,
+			b: "feature",
+		},
+		gitLab: gls,
+		hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+		editor: es,
+	}
+
+	_, err := c.CreateMR(context.Background(), CreateMRParams{
+		TargetBranch:        "master",
+		TitleTemplate:       "Generated title",
+		DescriptionTemplate: "Generated description",
+		MentionMode:         MentionModeBoth,
+		Edit:                true,
+	})
+	if err != nil {
+		t.Fatal("error creating MR", err)
+	}
+
+	expText := "Target: master\nLabels: \nReviewers: \n\nGenerated title\n\nGenerated description\n\n" + editHints
+	if es.text != expText {
+		t.Fatalf("exp edited text: %q, got: %q", expText, es.text)
+	}
+
+	if req.TargetBranch != "develop" ||
+		req.Title != "Fix crash" ||
+		req.Description != "## Details\nCrash on start" ||
+		req.Labels != "bug,backend" ||
+		!reflect.DeepEqual(req.ReviewerIDs, []int{12}) ||
+		!reflect.DeepEqual(req.AssigneeIDs, []int{123, 12}) {
+		t.Fatalf("edit is not applied: %+v", req)
+	}
+
+	es.edit = func(text string) string {
+		return "\n" + text[strings.Index(text, editScissors):]
+	}
+
+	req = gitlab.CreateMRRequest{}
+	_, err = c.CreateMR(context.Background(), CreateMRParams{
+		TargetBranch: "master",
+		Edit:         true,
+	})
+	if !errors.Is(err, ErrEditAborted) {
+		t.Fatal("expected ErrEditAborted, got", err)
+	}
+
+	if req.Title != "" {
+		t.Fatal("MR should not be created after abort")
+	}
+}
+
+// behindGit reports number of commits branch is behind by target.
+type behindGit struct {
+	gitStub
+	behind map[string]int
+}
+
+func (bg *behindGit) Behind(branch, target string) (int, error) {
+	return bg.behind[target], nil
+}
+
+func TestCreateMR_EditTarget(t *testing.T) {
+	var created bool
+	gls := &gitlabStub{
+		f: func(method string, arg interface{}) {
+			if method == "CreateMR" {
+				created = true
+			}
+		},
+	}
+
+	c := Core{
+		git: &behindGit{
+			gitStub: gitStub{r: testRemote, b: "feature"},
+			behind:  map[string]int{"develop": 3},
+		},
+		gitLab: gls,
+		hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+		editor: &editorStub{
+			edit: func(text string) string {
+				return strings.Replace(text, "Target: master", "Target: develop", 1)
+			},
+		},
+	}
+
+	_, err := c.CreateMR(context.Background(), CreateMRParams{
+		TargetBranch: "master",
+		Checks:       ChecksParams{BehindTarget: true},
+		Edit:         true,
+	})
+	if !errors.Is(err, ErrChecksFailed) {
+		t.Fatal("expected checks of edited target to fail, got", err)
+	}
+
+	if created {
+		t.Fatal("MR should not be created after failed checks")
+	}
+}
+
+func TestParseMREdit(t *testing.T) {
+	me := mrEdit{
+		TargetBranch: "master",
+		Title:        "Title: with colon",
+		Description:  "# Heading\n\ntext",
+		Labels:       []string{"a", "b"},
+		Reviewers:    []string{"@john"},
+	}
+
+	parsed, err := parseMREdit(formatMREdit(me))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(parsed, me) {
+		t.Fatalf("exp: %+v, got: %+v", me, parsed)
+	}
+
+	_, err = parseMREdit("Target: master\nLabels: a\n\n")
+	if !errors.Is(err, ErrEditAborted) {
+		t.Fatal("expected ErrEditAborted for empty title, got", err)
+	}
+}
@@ -1,6 +1,63 @@
 package glmt
 
+import (
+	"context"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/git"
+)
+
+// ErrConflict is returned by Git.CherryPick if commits conflict with base branch.
+var ErrConflict = git.ErrConflict
+
 type Git interface {
-	Remote() (string, error)
+	// Remote returns name and URL of remote with current branch.
+	Remote() (name string, url string, err error)
+	// TargetRemote returns URL of remote MRs are created to, it differs
+	// from Remote when working from fork.
+	TargetRemote() (string, error)
 	CurrentBranch() (string, error)
+	// Push pushes branch to remote and sets it as branch's upstream.
+	Push(ctx context.Context, remote, branch string) error
+	// Unpushed reports if branch has commits missing in remote.
+	Unpushed(remote, branch string) (bool, error)
+	// DirtyFiles returns modified and staged files of worktree.
+	DirtyFiles() ([]string, error)
+	// InSync reports if branch points to the same commit as its upstream branch on remote.
+	InSync(remote, branch string) (bool, error)
+	// Behind returns number of target branch's commits missing in branch.
+	Behind(branch, target string) (int, error)
+	// BranchCommits returns branch's commits missing in target branch, newest first.
+	BranchCommits(branch, target string) ([]Commit, error)
+	// Divergence returns numbers of branch's commits missing in target branch
+	// and of target branch's commits missing in branch.
+	Divergence(branch, target string) (ahead int, behind int, err error)
+	// DiffStat returns statistics of changes between merge base of branch
+	// and target branch and branch.
+	DiffStat(branch, target string) (DiffStat, error)
+	// ReadDir returns names of files in worktree's directory, missing
+	// directory has no files.
+	ReadDir(dir string) ([]string, error)
+	ReadFile(path string) ([]byte, error)
+	// BranchStack returns local branches branch is stacked on, starting from
+	// the branch nearest to target and ending with branch itself.
+	BranchStack(branch, target string) ([]string, error)
+	// RemoteBranches returns names of target remote's branches.
+	RemoteBranches() ([]string, error)
+	// Upstream returns name of branch's upstream branch on target remote,
+	// it is empty if branch does not track target remote's branch.
+	Upstream(branch string) (string, error)
+	// CherryPick creates branch from target remote's base branch and cherry-picks
+	// commits onto it in order, merge commits are skipped. Existing branch is
+	// reused without cherry-picking. On conflict branch is not created and error
+	// wrapping ErrConflict is returned.
+	CherryPick(ctx context.Context, branch, base string, commits []string) error
+	// FetchMR fetches head of target remote's MR, so its commits can be cherry-picked.
+	FetchMR(ctx context.Context, iid int64) error
+	// RangeCommits returns commits reachable from revision to, but not from
+	// revision from, newest first. Empty from means all commits.
+	RangeCommits(from, to string) ([]Commit, error)
 }
+
+type Commit = git.Commit
+
+type DiffStat = git.DiffStat
@@ -13,9 +13,11 @@ import (
 
 	"github.com/rs/zerolog/log"
 
+	"gitlab.com/gitlab-merge-tool/glmt/internal/editor"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/gerr"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/hooks"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/jira"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/notifier"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/team"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/templating"
@@ -32,6 +34,25 @@ func init() {
 
 var (
 	ErrNotification = errors.New("notification error")
+	ErrMRExists     = errors.New("merge request already exists")
+	ErrNotPushed    = errors.New("branch is not pushed")
+)
+
+// Modes of handling already existing MR for the same source and target branches.
+const (
+	// ExistingMRUpdate updates title, description and labels of existing MR.
+	ExistingMRUpdate = "update"
+	// ExistingMRKeep returns existing MR as is.
+	ExistingMRKeep = "keep"
+	// ExistingMRFail fails with ErrMRExists.
+	ExistingMRFail = "fail"
+)
+
+// Statuses of MR returned by CreateMR.
+const (
+	MRStatusCreated  = "created"
+	MRStatusUpdated  = "updated"
+	MRStatusExisting = "existing"
 )
 
 func NewGLMT(
@@ -40,6 +61,8 @@ func NewGLMT(
 	notifier notifier.Notifier,
 	teamSource team.TeamFileSource,
 	hooks hooks.Runner,
+	jira jira.Jira,
+	editor editor.Editor,
 ) *Core {
 	return &Core{
 		git:        git,
@@ -47,6 +70,8 @@ func NewGLMT(
 		notifier:   notifier,
 		teamSource: teamSource,
 		hooks:      hooks,
+		jira:       jira,
+		editor:     editor,
 	}
 }
 
@@ -56,9 +81,14 @@ type Core struct {
 	notifier   notifier.Notifier
 	teamSource team.TeamFileSource
 	hooks      hooks.Runner
+	// jira is nil if Jira is not configured.
+	jira   jira.Jira
+	editor editor.Editor
 }
 
 type CreateMRParams struct {
+	// SourceBranch is a branch MR is created from, default is current branch.
+	SourceBranch        string
 	TargetBranch        string
 	BranchRegexp        *regexp.Regexp
 	TitleTemplate       string
@@ -68,7 +98,37 @@ type CreateMRParams struct {
 	NotificationMessage string
 	MentionsCount       int
 	LabelVars           []string
+	Labels              LabelsParams
 	IgnoreHooks         bool
+	// ExistingMR is a mode of handling already opened MR, one of ExistingMR* constants.
+	// Default is ExistingMRUpdate.
+	ExistingMR string
+	// Renotify sends notifications even if MR already existed.
+	Renotify bool
+	// MentionMode tells how to use mentioned members, one of MentionMode* constants.
+	MentionMode string
+	// Draft creates MR as draft, notifications are not sent for draft MR.
+	Draft bool
+	// Push pushes current branch before creating MR, otherwise
+	// MR is not created if branch has unpushed commits.
+	Push bool
+	// Checks are made before push and any request to GitLab, target changed
+	// in editor is checked again before MR is created. UpToDate is skipped
+	// with Push.
+	Checks ChecksParams
+	Issue  IssueParams
+	Jira   JiraParams
+	// Template is a name of MR template from .gitlab/merge_request_templates,
+	// it replaces DescriptionTemplate.
+	Template string
+	// ProjectTemplates are default MR templates, see TemplatesParams.
+	ProjectTemplates map[string]string
+	// Edit opens rendered MR in editor before creating it.
+	Edit bool
+	// TextArgs are additional template variables, they override other variables.
+	TextArgs map[string]interface{}
+	// LenientTemplates renders unknown variables empty and ignores errors of templates.
+	LenientTemplates bool
 }
 
 type MergeRequest struct {
@@ -77,6 +137,9 @@ type MergeRequest struct {
 	ProjectID int64     `json:"project_id"`
 	CreatedAt time.Time `json:"created_at"`
 	URL       string    `json:"url"`
+	// Status is one of MRStatus* constants.
+	Status       string `json:"status"`
+	ChangesCount string `json:"changes_count"`
 }
 
 func (c *Core) CreateMR(ctx context.Context, params CreateMRParams) (MergeRequest, error) {
@@ -85,17 +148,34 @@ func (c *Core) CreateMR(ctx context.Context, params CreateMRParams) (MergeReques
 		return mr, errors.New("target branch is required")
 	}
 
-	br, err := c.git.CurrentBranch()
+	ri, err := c.repoInfo()
+	if err != nil {
+		return mr, err
+	}
+
+	if params.SourceBranch != "" {
+		ri.branch = params.SourceBranch
+	}
+
+	br, p := ri.branch, ri.project
+
+	// branch is pushed after checks, so it is in sync with remote then
+	checks := params.Checks
+	if params.Push {
+		checks.UpToDate = false
+	}
+
+	err = c.runChecks(ri, params.TargetBranch, checks)
 	if err != nil {
 		return mr, err
 	}
 
-	r, err := c.git.Remote()
+	err = c.pushBranch(ctx, ri, params.Push)
 	if err != nil {
 		return mr, err
 	}
 
-	p, err := projectFromRemote(r)
+	dt, err := c.descriptionTemplate(ctx, ri, params)
 	if err != nil {
 		return mr, err
 	}
@@ -115,35 +195,70 @@ func (c *Core) CreateMR(ctx context.Context, params CreateMRParams) (MergeReques
 		ms = Mentions(tm, cu.Username, p, params.MentionsCount)
 	}
 
-	ta := getTextArgs(br, p, r, cu.Username, params, ms)
+	reviewers, assignees, err := c.mentionIDs(ctx, params.MentionMode, cu.ID, ms)
+	if err != nil {
+		return mr, err
+	}
 
-	var t string
-	if params.TitleTemplate != "" {
-		t = templating.CreateText("title", params.TitleTemplate, ta)
+	ta := getTextArgs(ri, cu.Username, params, ms)
+	for k, v := range params.TextArgs {
+		ta[k] = v
 	}
 
-	t = strings.TrimSpace(t)
-	if t == "" {
-		t = br
+	cs := c.addCommitArgs(ctx, ri, params.TargetBranch, ta)
+
+	is, err := c.addIssueArgs(ctx, ri, params.Issue, ta)
+	if err != nil {
+		return mr, err
 	}
 
-	var d string
-	if params.DescriptionTemplate != "" {
-		d = templating.CreateText("description", params.DescriptionTemplate, ta)
+	ji, err := c.addJiraArgs(ctx, params.Jira, ta)
+	if err != nil {
+		return mr, err
 	}
 
-	d = strings.TrimSpace(d)
-	if d == "" {
-		d = "Merge " + br + " into " + params.TargetBranch
+	req, err := newMRRequest(params, br, dt, ta, cs, is, ji)
+	if err != nil {
+		return mr, err
+	}
+
+	req.Project = ri.sourceProject
+	req.AssigneeID = cu.ID
+	req.AssigneeIDs = assignees
+	req.ReviewerIDs = reviewers
+
+	if params.Edit {
+		ms, err = c.editMR(ctx, params.MentionMode, ms, &req)
+		if err != nil {
+			return mr, err
+		}
+
+		if req.TargetBranch != params.TargetBranch {
+			err = c.runChecks(ri, req.TargetBranch, checks)
+			if err != nil {
+				return mr, err
+			}
+
+			c.addCommitArgs(ctx, ri, req.TargetBranch, ta)
+		}
+
+		ta[TmpVarTargetBranchName] = req.TargetBranch
+		ta[TmpVarGitlabMentions] = gitlabMentions(ms)
+	}
+
+	req.Labels, err = c.validateLabels(ctx, p, params.Labels, req.Labels)
+	if err != nil {
+		return mr, err
 	}
 
 	if !params.IgnoreHooks {
-		err = c.hooks.RunBefore(ctx, hooks.Params(ta))
+		err = c.hooks.RunBefore(ctx, hookParams(ta))
 		if err != nil {
 			return mr, fmt.Errorf("hooks precondition failed: %w", err)
 		}
 	}
 
+	t, d := req.Title, req.Description
 	ta[TmpVarTitle] = t
 	ta[TmpVarDescription] = d
 
@@ -153,41 +268,38 @@ func (c *Core) CreateMR(ctx context.Context, params CreateMRParams) (MergeReques
 		Str("description", d).
 		Msg("create mr")
 
-	gmr, err := c.gitLab.CreateMR(ctx, gitlab.CreateMRRequest{
-		Project:            p,
-		SourceBranch:       br,
-		TargetBranch:       params.TargetBranch,
-		Title:              t,
-		Description:        d,
-		Squash:             params.Squash,
-		RemoveSourceBranch: params.RemoveBranch,
-		AssigneeID:         cu.ID,
-		Labels:             labelsFrom(ta, params.LabelVars),
-	})
+	if ri.fork() {
+		tp, err := c.gitLab.Project(ctx, ri.project)
+		if err != nil {
+			return mr, err
+		}
+
+		req.TargetProjectID = tp.ID
+	}
+
+	mr, err = c.submitMR(ctx, params, ri, req)
 	if err != nil {
 		return mr, err
 	}
 
+	// MR already exists, so failed transition does not stop hooks and notifications
+	trErr := c.transitionJira(ctx, params.Jira, ji)
+
 	ta[TmpVarTitle] = t
 	ta[TmpVarDescription] = d
-	ta[TmpVarMRURL] = gmr.URL
-	ta[TmpVarMRChangesCount] = gmr.ChangesCount
+	ta[TmpVarMRURL] = mr.URL
+	ta[TmpVarMRChangesCount] = mr.ChangesCount
 
 	if !params.IgnoreHooks {
-		err = c.hooks.RunAfter(ctx, hooks.Params(ta))
+		err = c.hooks.RunAfter(ctx, hookParams(ta))
 		if err != nil {
 			return mr, fmt.Errorf("hooks postcondition failed: %w", err)
 		}
 	}
 
-	mr.ID = gmr.ID
-	mr.IID = gmr.IID
-	mr.ProjectID = gmr.ProjectID
-	mr.CreatedAt = gmr.CreatedAt
-	mr.URL = gmr.URL
-
-	if c.notifier != nil {
-		err = c.notifier.Send(ctx, ta, params.NotificationMessage, ms)
+	notify := mr.Status == MRStatusCreated || params.Renotify
+	if c.notifier != nil && notify && !params.Draft {
+		err = c.notifier.Send(ctx, templateArgs(ta), params.NotificationMessage, ms)
 		if err != nil {
 			err = gerr.NewNestedError(ErrNotification, err)
 		}
@@ -197,22 +309,252 @@ func (c *Core) CreateMR(ctx context.Context, params CreateMRParams) (MergeReques
 			Msg("notification")
 	}
 
+	if err == nil {
+		err = trErr
+	}
+
 	return mr, err
 }
 
-func labelsFrom(ta map[string]string, labelVars []string) string {
-	labels := make([]string, 0, len(labelVars))
+// newMRRequest assembles MR's title, description and labels from templates and
+// variables, issue and Jira issue may be nil. Nothing is requested, so Render
+// previews the same texts CreateMR submits.
+func newMRRequest(
+	params CreateMRParams,
+	branch string,
+	descriptionTemplate string,
+	ta map[string]interface{},
+	cs []Commit,
+	is *gitlab.Issue,
+	ji *jira.Issue,
+) (gitlab.CreateMRRequest, error) {
+	var req gitlab.CreateMRRequest
+
+	t, err := templating.CreateText("title", params.TitleTemplate, templateArgs(ta), params.LenientTemplates)
+	if err != nil {
+		return req, err
+	}
+
+	t = strings.TrimSpace(t)
+	if t == "" && is != nil {
+		t = is.Title
+	}
+	if t == "" && ji != nil {
+		t = ji.Summary
+	}
+	if t == "" && len(cs) == 1 {
+		t = cs[0].Subject
+	}
+	if t == "" {
+		t = branch
+	}
+
+	if params.Draft && !isDraftTitle(t) {
+		t = draftPrefix + t
+	}
+
+	d, err := templating.CreateText("description", descriptionTemplate, templateArgs(ta), params.LenientTemplates)
+	if err != nil {
+		return req, err
+	}
+
+	d = strings.TrimSpace(d)
+	if d == "" {
+		d = "Merge " + branch + " into " + params.TargetBranch
+	}
 
-	for _, lv := range labelVars {
-		val, ok := ta[lv]
-		if !ok {
-			continue
+	req = gitlab.CreateMRRequest{
+		SourceBranch:       branch,
+		TargetBranch:       params.TargetBranch,
+		Title:              t,
+		Description:        closeIssue(params.Issue, is, d),
+		Squash:             params.Squash,
+		RemoveSourceBranch: params.RemoveBranch,
+		Labels:             labelsFrom(ta, params.LabelVars, params.Labels, branch),
+	}
+
+	applyIssue(params.Issue, is, &req)
+	req.Labels = changeLabels(params.Labels, req.Labels)
+
+	return req, nil
+}
+
+// submitMR creates MR or, if there is already opened MR for the same branches,
+// handles it according to params.ExistingMR.
+func (c *Core) submitMR(ctx context.Context, params CreateMRParams, ri repoInfo, req gitlab.CreateMRRequest) (MergeRequest, error) {
+	var mr MergeRequest
+
+	switch params.ExistingMR {
+	case "", ExistingMRUpdate, ExistingMRKeep, ExistingMRFail:
+	default:
+		return mr, errors.New("unknown mode for existing MR: " + params.ExistingMR)
+	}
+
+	emrs, err := c.branchMRs(ctx, ri, req.TargetBranch)
+	if err != nil {
+		return mr, err
+	}
+
+	if len(emrs) == 0 {
+		gmr, err := c.gitLab.CreateMR(ctx, req)
+		if err != nil {
+			return mr, err
+		}
+
+		mr.ID = gmr.ID
+		mr.IID = gmr.IID
+		mr.ProjectID = gmr.ProjectID
+		mr.CreatedAt = gmr.CreatedAt
+		mr.URL = gmr.URL
+		mr.ChangesCount = gmr.ChangesCount
+		mr.Status = MRStatusCreated
+
+		return mr, nil
+	}
+
+	gmr := emrs[0]
+	log.Ctx(ctx).Debug().
+		Str("url", gmr.URL).
+		Str("mode", params.ExistingMR).
+		Msg("found existing mr")
+
+	switch params.ExistingMR {
+	case ExistingMRFail:
+		return mr, fmt.Errorf("%w: %s", ErrMRExists, gmr.URL)
+	case ExistingMRKeep:
+		mr.Status = MRStatusExisting
+	default:
+		// draft mode is for new MRs, ready MR is not made draft again
+		title := req.Title
+		if params.Draft && !isDraftMR(gmr) {
+			title = trimDraft(title)
 		}
 
-		labels = append(labels, val)
+		gmr, err = c.gitLab.UpdateMR(ctx, gitlab.UpdateMRRequest{
+			Project:     ri.project,
+			IID:         gmr.IID,
+			Title:       title,
+			Description: req.Description,
+			Labels:      req.Labels,
+			AssigneeIDs: req.AssigneeIDs,
+			ReviewerIDs: req.ReviewerIDs,
+			MilestoneID: req.MilestoneID,
+		})
+		if err != nil {
+			return mr, err
+		}
+
+		mr.Status = MRStatusUpdated
+	}
+
+	mr.ID = gmr.ID
+	mr.IID = gmr.IID
+	mr.ProjectID = gmr.ProjectID
+	mr.CreatedAt = gmr.CreatedAt
+	mr.URL = gmr.URL
+	mr.ChangesCount = gmr.ChangesCount
+
+	return mr, nil
+}
+
+// pushBranch pushes current branch if push is true, otherwise checks
+// that there are no unpushed commits.
+func (c *Core) pushBranch(ctx context.Context, ri repoInfo, push bool) error {
+	if push {
+		return c.git.Push(ctx, ri.remoteName, ri.branch)
+	}
+
+	unpushed, err := c.git.Unpushed(ri.remoteName, ri.branch)
+	if err != nil {
+		return err
+	}
+
+	if unpushed {
+		return fmt.Errorf("%w: %s has commits missing in %s, push them or use --push flag",
+			ErrNotPushed, ri.branch, ri.remoteName)
+	}
+
+	return nil
+}
+
+// repoInfo describes current state of local repository.
+type repoInfo struct {
+	branch     string
+	remote     string
+	remoteName string
+	// project is a project MRs are created to.
+	project string
+	// sourceProject is a project of remote, it differs from project for forks.
+	sourceProject string
+}
+
+func (ri repoInfo) fork() bool {
+	return ri.sourceProject != ri.project
+}
+
+func (c *Core) repoInfo() (repoInfo, error) {
+	var ri repoInfo
+
+	br, err := c.git.CurrentBranch()
+	if err != nil {
+		return ri, err
+	}
+
+	rn, r, err := c.git.Remote()
+	if err != nil {
+		return ri, err
+	}
+
+	sp, err := projectFromRemote(r)
+	if err != nil {
+		return ri, err
+	}
+
+	tr, err := c.git.TargetRemote()
+	if err != nil {
+		return ri, err
+	}
+
+	p, err := projectFromRemote(tr)
+	if err != nil {
+		return ri, err
+	}
+
+	ri.branch = br
+	ri.remote = r
+	ri.remoteName = rn
+	ri.project = p
+	ri.sourceProject = sp
+
+	return ri, nil
+}
+
+// branchMRs lists opened MRs from current branch to target (any target if empty).
+// For forks MRs from other projects with the same branch name are skipped.
+func (c *Core) branchMRs(ctx context.Context, ri repoInfo, target string) ([]gitlab.MergeRequest, error) {
+	mrs, err := c.gitLab.ListMRs(ctx, gitlab.ListMRsRequest{
+		Project:      ri.project,
+		State:        "opened",
+		SourceBranch: ri.branch,
+		TargetBranch: target,
+	})
+	if err != nil || !ri.fork() {
+		return mrs, err
+	}
+
+	sp, err := c.gitLab.Project(ctx, ri.sourceProject)
+	if err != nil {
+		return nil, err
+	}
+
+	fmrs := mrs[:0]
+	for _, mr := range mrs {
+		if mr.SourceProjectID == sp.ID {
+			fmrs = append(fmrs, mr)
+		}
 	}
 
-	return strings.Join(labels, ",")
+	return fmrs, nil
 }
 
 func projectFromRemote(rem string) (string, error) {
@@ -2,8 +2,11 @@ package glmt
 
 import (
 	"context"
+	"errors"
+	"os"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 
 	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
@@ -11,6 +14,7 @@ import (
 	hooksi "gitlab.com/gitlab-merge-tool/glmt/internal/hooks/impl"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/team"
 	teami "gitlab.com/gitlab-merge-tool/glmt/internal/team/impl"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/templating"
 )
 
 func TestRemoteParse(t *testing.T) {
@@ -46,12 +50,13 @@ func TestRemoteParse(t *testing.T) {
 }
 
 func TestTextArgs(t *testing.T) {
-	expTa := map[string]string{
+	expTa := map[string]interface{}{
 		TmpVarProjectName:      "prj1",
 		TmpVarBranchName:       "feature/TASK-123/some-description",
 		TmpVarTargetBranchName: "develop",
 		TmpVarGitlabMentions:   "@test",
-		TmpVarRemote:           "origin",
+		TmpVarRemote:           "git@gitlab.com:group/prj1.git",
+		TmpVarRemoteName:       "origin",
 		TmpVarUsername:         "xxx",
 
 		"Task":              "TASK-123",
@@ -60,23 +65,45 @@ func TestTextArgs(t *testing.T) {
 	}
 
 	params := CreateMRParams{
-		TargetBranch: expTa["TargetBranchName"],
+		TargetBranch: expTa["TargetBranchName"].(string),
 		BranchRegexp: regexp.MustCompile(`(?P<TaskType>.*)/(?P<Task>.*)/(?P<BranchDescription>.*)`),
 	}
 
 	members := []*team.Member{{
 		Username: "test",
 	}}
-	ta := getTextArgs(expTa["BranchName"], expTa["ProjectName"], "origin", "xxx", params, members)
+	ri := repoInfo{
+		branch:     expTa[TmpVarBranchName].(string),
+		remote:     expTa[TmpVarRemote].(string),
+		remoteName: expTa[TmpVarRemoteName].(string),
+		project:    expTa[TmpVarProjectName].(string),
+	}
+	ta := getTextArgs(ri, "xxx", params, members)
 
 	if !reflect.DeepEqual(expTa, ta) {
 		t.Fatalf("expected ta: %+v, got %+v", expTa, ta)
 	}
 }
 
+func TestTemplateArgs(t *testing.T) {
+	const tmpl = `{{if eq .ChangesCount "1"}}one change{{end}}`
+
+	// zero values must have types of real values, so templates rendered
+	// without some variables fail the same way as with them
+	for _, ta := range []map[string]interface{}{
+		{TmpVarMRChangesCount: "1"},
+		{},
+	} {
+		_, err := templating.Render("test", tmpl, templateArgs(ta))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
 func TestCreateMR(t *testing.T) {
 	gs := &gitStub{
-		r: "https://github.com/hummerd/client_golang.git",
+		r: testRemote,
 		b: "feature/TASK-123/add-some-feature",
 	}
 
@@ -139,23 +166,368 @@ func TestCreateMR(t *testing.T) {
 	}
 }
 
+func TestCreateMR_Existing(t *testing.T) {
+	gs := &gitStub{
+		r: testRemote,
+		b: "feature/TASK-123/add-some-feature",
+	}
+
+	// existing MR is ready, so it is not made draft again
+	cp := CreateMRParams{
+		TitleTemplate: "{{.Task}}",
+		TargetBranch:  "develop",
+		BranchRegexp:  regexp.MustCompile("(?P<TaskType>.*)/(?P<Task>.*)/(?P<BranchDescription>.*)"),
+		Draft:         true,
+	}
+
+	var calls []string
+	gls := &gitlabStub{
+		f: func(method string, arg interface{}) {
+			calls = append(calls, method)
+
+			if method == "UpdateMR" {
+				exp := gitlab.UpdateMRRequest{
+					Project:     "hummerd/client_golang",
+					IID:         7,
+					Title:       "TASK-123",
+					Description: "Merge " + gs.b + " into develop",
+				}
+
+				if !reflect.DeepEqual(exp, arg) {
+					t.Fatalf("expected update request: %+v, got %+v", exp, arg)
+				}
+			}
+		},
+		mrs: []gitlab.MergeRequest{{ID: 70, IID: 7}},
+	}
+
+	hs := hooksi.NewHooks(config.Hooks{}, nil, nil)
+	c := Core{
+		git:    gs,
+		gitLab: gls,
+		hooks:  hs,
+	}
+
+	mr, err := c.CreateMR(context.Background(), cp)
+	if err != nil {
+		t.Fatal("error creating MR", err)
+	}
+
+	if mr.Status != MRStatusUpdated || mr.IID != 7 {
+		t.Fatalf("wrong MR: %+v", mr)
+	}
+
+	for _, m := range calls {
+		if m == "CreateMR" {
+			t.Fatal("MR should not be created")
+		}
+	}
+
+	cp.ExistingMR = ExistingMRFail
+	_, err = c.CreateMR(context.Background(), cp)
+	if !errors.Is(err, ErrMRExists) {
+		t.Fatal("expected ErrMRExists, got", err)
+	}
+}
+
+func TestCreateMR_Commits(t *testing.T) {
+	gs := &gitStub{
+		r: testRemote,
+		b: "feature",
+		commits: []Commit{{
+			ShortSHA: "0123abcd",
+			Subject:  "Add feature",
+		}},
+		diff: DiffStat{FilesChanged: 2, Insertions: 10, Deletions: 3},
+	}
+
+	var req gitlab.CreateMRRequest
+	gls := &gitlabStub{
+		f: func(method string, arg interface{}) {
+			if method == "CreateMR" {
+				req = arg.(gitlab.CreateMRRequest)
+			}
+		},
+	}
+
+	c := Core{
+		git:    gs,
+		gitLab: gls,
+		hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+	}
+
+	_, err := c.CreateMR(context.Background(), CreateMRParams{
+		TargetBranch: "master",
+		DescriptionTemplate: "{{range .Commits}}* {{.Subject}} ({{.ShortSHA}})\n{{end}}" +
+			"{{.FilesChanged}} files, +{{.Insertions}} -{{.Deletions}}",
+	})
+	if err != nil {
+		t.Fatal("error creating MR", err)
+	}
+
+	if req.Title != "Add feature" {
+		t.Fatal("title should be commit subject, got", req.Title)
+	}
+
+	exp := "* Add feature (0123abcd)\n2 files, +10 -3"
+	if req.Description != exp {
+		t.Fatalf("exp description: %q, got: %q", exp, req.Description)
+	}
+}
+
+// noDiffGit fails to compute diff statistics.
+type noDiffGit struct {
+	gitStub
+}
+
+func (ng *noDiffGit) DiffStat(branch, target string) (DiffStat, error) {
+	return DiffStat{}, errors.New("no merge base")
+}
+
+func TestAddCommitArgs_NoDiffStat(t *testing.T) {
+	c := Core{git: &noDiffGit{gitStub{commits: []Commit{{Subject: "Add feature"}}}}}
+
+	ta := map[string]interface{}{}
+	cs := c.addCommitArgs(context.Background(), repoInfo{branch: "feature"}, "master", ta)
+
+	if len(cs) != 1 || !reflect.DeepEqual(ta[TmpVarCommits], cs) || ta[TmpVarFilesChanged] != 0 {
+		t.Fatalf("commits should be kept without diff statistics: %v, %v", cs, ta)
+	}
+}
+
+// pushGit has unpushed branch until Push is called.
+type pushGit struct {
+	gitStub
+	unpushed bool
+	pushed   bool
+}
+
+func (pg *pushGit) Push(ctx context.Context, remote, branch string) error {
+	pg.pushed = true
+	pg.unpushed = false
+	return nil
+}
+
+func (pg *pushGit) Unpushed(remote, branch string) (bool, error) {
+	return pg.unpushed, nil
+}
+
+func TestCreateMR_Push(t *testing.T) {
+	gs := &pushGit{
+		gitStub:  gitStub{r: testRemote, b: "feature"},
+		unpushed: true,
+	}
+
+	created := false
+	gls := &gitlabStub{
+		f: func(method string, arg interface{}) {
+			if method == "CreateMR" {
+				created = true
+			}
+		},
+	}
+
+	hs := hooksi.NewHooks(config.Hooks{}, nil, nil)
+	c := Core{
+		git:    gs,
+		gitLab: gls,
+		hooks:  hs,
+	}
+
+	cp := CreateMRParams{TargetBranch: "master"}
+	_, err := c.CreateMR(context.Background(), cp)
+	if !errors.Is(err, ErrNotPushed) {
+		t.Fatal("expected ErrNotPushed, got", err)
+	}
+
+	if created {
+		t.Fatal("MR should not be created for unpushed branch")
+	}
+
+	cp.Push = true
+	_, err = c.CreateMR(context.Background(), cp)
+	if err != nil {
+		t.Fatal("error creating MR", err)
+	}
+
+	if !gs.pushed || !created {
+		t.Fatal("branch should be pushed and MR created")
+	}
+}
+
+// forkGitLab knows IDs of fork and upstream projects.
+type forkGitLab struct {
+	gitlabStub
+	// projects are IDs by path.
+	projects map[string]int64
+}
+
+func (fg *forkGitLab) Project(ctx context.Context, project string) (gitlab.Project, error) {
+	fg.f("Project", project)
+	return gitlab.Project{ID: fg.projects[project], PathWithNamespace: project}, nil
+}
+
+func TestCreateMR_Fork(t *testing.T) {
+	gs := &gitStub{
+		r:  "git@gitlab.com:contributor/client_golang.git",
+		tr: "git@gitlab.com:hummerd/client_golang.git",
+		b:  "fix",
+	}
+
+	var req gitlab.CreateMRRequest
+	gls := &forkGitLab{
+		gitlabStub: gitlabStub{
+			f: func(method string, arg interface{}) {
+				if method == "CreateMR" {
+					req = arg.(gitlab.CreateMRRequest)
+				}
+			},
+			// MR from other fork with the same branch name
+			mrs: []gitlab.MergeRequest{{ID: 70, IID: 7, SourceProjectID: 3}},
+		},
+		projects: map[string]int64{
+			"contributor/client_golang": 2,
+			"hummerd/client_golang":     1,
+		},
+	}
+
+	hs := hooksi.NewHooks(config.Hooks{}, nil, nil)
+	c := Core{
+		git:    gs,
+		gitLab: gls,
+		hooks:  hs,
+	}
+
+	mr, err := c.CreateMR(context.Background(), CreateMRParams{
+		TargetBranch: "master",
+		ExistingMR:   ExistingMRFail,
+	})
+	if err != nil {
+		t.Fatal("error creating MR", err)
+	}
+
+	if mr.Status != MRStatusCreated {
+		t.Fatalf("wrong MR: %+v", mr)
+	}
+
+	if req.Project != "contributor/client_golang" || req.TargetProjectID != 1 {
+		t.Fatalf("wrong create request: %+v", req)
+	}
+}
+
+// testRemote is URL of current branch's remote in tests.
+const testRemote = "https://github.com/hummerd/client_golang.git"
+
+// gitStub is a repository with clean pushed branch, features needing other
+// states embed it and override methods.
 type gitStub struct {
 	r string
 	b string
+	// tr is target remote, r is used if empty.
+	tr string
+	// commits are returned by BranchCommits and RangeCommits.
+	commits []Commit
+	// diff is returned by DiffStat.
+	diff DiffStat
 }
 
-func (gs *gitStub) Remote() (string, error) {
-	return gs.r, nil
+func (gs *gitStub) Remote() (string, string, error) {
+	return "origin", gs.r, nil
+}
+
+func (gs *gitStub) TargetRemote() (string, error) {
+	if gs.tr == "" {
+		return gs.r, nil
+	}
+
+	return gs.tr, nil
 }
 
 func (gs *gitStub) CurrentBranch() (string, error) {
 	return gs.b, nil
 }
 
+func (gs *gitStub) Push(ctx context.Context, remote, branch string) error {
+	return nil
+}
+
+func (gs *gitStub) Unpushed(remote, branch string) (bool, error) {
+	return false, nil
+}
+
+func (gs *gitStub) DirtyFiles() ([]string, error) {
+	return nil, nil
+}
+
+func (gs *gitStub) InSync(remote, branch string) (bool, error) {
+	return true, nil
+}
+
+func (gs *gitStub) Behind(branch, target string) (int, error) {
+	return 0, nil
+}
+
+func (gs *gitStub) BranchCommits(branch, target string) ([]Commit, error) {
+	return gs.commits, nil
+}
+
+func (gs *gitStub) Divergence(branch, target string) (int, int, error) {
+	return len(gs.commits), 0, nil
+}
+
+func (gs *gitStub) DiffStat(branch, target string) (DiffStat, error) {
+	return gs.diff, nil
+}
+
+func (gs *gitStub) CherryPick(ctx context.Context, branch, base string, commits []string) error {
+	return nil
+}
+
+func (gs *gitStub) FetchMR(ctx context.Context, iid int64) error {
+	return nil
+}
+
+func (gs *gitStub) RangeCommits(from, to string) ([]Commit, error) {
+	return gs.commits, nil
+}
+
+func (gs *gitStub) RemoteBranches() ([]string, error) {
+	return nil, nil
+}
+
+func (gs *gitStub) Upstream(branch string) (string, error) {
+	return "", nil
+}
+
+func (gs *gitStub) BranchStack(branch, target string) ([]string, error) {
+	return nil, nil
+}
+
+func (gs *gitStub) ReadDir(dir string) ([]string, error) {
+	return nil, nil
+}
+
+func (gs *gitStub) ReadFile(file string) ([]byte, error) {
+	return nil, os.ErrNotExist
+}
+
 type gitlabCallback func(string, interface{})
 
+// gitlabStub reports calls to f and implements MR creation and search, features
+// needing other requests embed it and implement them.
 type gitlabStub struct {
+	// GitLab is nil, so requests not implemented by stub panic.
+	gitlab.GitLab
+
 	f gitlabCallback
+	// mrs are returned by ListMRs.
+	mrs []gitlab.MergeRequest
+	// listMRs is used by ListMRs instead of mrs if set.
+	listMRs func(req gitlab.ListMRsRequest) []gitlab.MergeRequest
+	// users are IDs by username returned by UserByUsername.
+	users map[string]int
+	// mr is returned by GetMR.
+	mr gitlab.MergeRequest
 }
 
 func (gls *gitlabStub) CreateMR(ctx context.Context, req gitlab.CreateMRRequest) (gitlab.CreateMRResponse, error) {
@@ -171,3 +543,59 @@ func (gls *gitlabStub) CurrentUser(ctx context.Context) (gitlab.UserResponse, er
 		ID: 123,
 	}, nil
 }
+
+func (gls *gitlabStub) ListMRs(ctx context.Context, req gitlab.ListMRsRequest) ([]gitlab.MergeRequest, error) {
+	gls.f("ListMRs", req)
+	if gls.listMRs != nil {
+		return gls.listMRs(req), nil
+	}
+
+	return gls.mrs, nil
+}
+
+func (gls *gitlabStub) GetMR(ctx context.Context, project string, iid int64) (gitlab.MergeRequest, error) {
+	gls.f("GetMR", iid)
+	return gls.mr, nil
+}
+
+func (gls *gitlabStub) UpdateMR(ctx context.Context, req gitlab.UpdateMRRequest) (gitlab.MergeRequest, error) {
+	gls.f("UpdateMR", req)
+
+	mr := gls.mr
+	mr.ID, mr.IID, mr.Title = req.IID, req.IID, req.Title
+	return mr, nil
+}
+
+func (gls *gitlabStub) UserByUsername(ctx context.Context, username string) (gitlab.UserResponse, error) {
+	gls.f("UserByUsername", username)
+
+	id, ok := gls.users[username]
+	if !ok {
+		return gitlab.UserResponse{}, errors.New("user not found")
+	}
+
+	return gitlab.UserResponse{
+		ID:       id,
+		Username: username,
+	}, nil
+}
+
+func TestValidateTemplates(t *testing.T) {
+	br := regexp.MustCompile(`(?P<TaskType>\w+)/(?P<Task>.+)`)
+
+	err := ValidateTemplates(br, map[string]string{
+		"mr.title":       "{{.Task}} {{.IssueTitle}}",
+		"mr.description": "{{range .Commits}}{{.Subject}}{{end}} {{.Title}}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ValidateTemplates(br, map[string]string{
+		"mr.title":                  "{{.Tsk}}",
+		"notifier.telegram.message": "{{.Description",
+	})
+	if err == nil || !strings.Contains(err.Error(), "Tsk") || !strings.Contains(err.Error(), "notifier.telegram.message") {
+		t.Fatal("exp both templates reported, got", err)
+	}
+}
@@ -0,0 +1,140 @@
+package glmt
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+)
+
+const (
+	TmpVarIssueTitle     = "IssueTitle"
+	TmpVarIssueLabels    = "IssueLabels"
+	TmpVarIssueMilestone = "IssueMilestone"
+	TmpVarIssueURL       = "IssueURL"
+)
+
+// issueNumberRegExp matches issue number in value of branch regexp group,
+// like "123", "#123" or "TASK-123".
+var issueNumberRegExp = regexp.MustCompile(`(\d+)\D*$`)
+
+// IssueParams configures GitLab issue referenced by current branch.
+type IssueParams struct {
+	// Var is a name of branch regexp group with issue number, empty disables issues.
+	Var string
+	// Close appends "Closes #N" to MR description.
+	Close bool
+	// CopyLabels adds issue's labels to MR.
+	CopyLabels bool
+	// CopyMilestone sets issue's milestone to MR.
+	CopyMilestone bool
+}
+
+// addIssueArgs fetches issue referenced by branch and adds its variables to text
+// args. Nil is returned if branch has no issue number.
+func (c *Core) addIssueArgs(
+	ctx context.Context,
+	ri repoInfo,
+	params IssueParams,
+	ta map[string]interface{},
+) (*gitlab.Issue, error) {
+	ta[TmpVarIssueTitle] = ""
+	ta[TmpVarIssueLabels] = ""
+	ta[TmpVarIssueMilestone] = ""
+	ta[TmpVarIssueURL] = ""
+
+	if params.Var == "" {
+		return nil, nil
+	}
+
+	v, _ := ta[params.Var].(string)
+	m := issueNumberRegExp.FindStringSubmatch(v)
+	if m == nil {
+		log.Ctx(ctx).Debug().
+			Str("value", v).
+			Msg("no issue number in branch")
+
+		return nil, nil
+	}
+
+	iid, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("can not parse issue number %s: %w", m[1], err)
+	}
+
+	is, err := c.gitLab.Issue(ctx, ri.project, iid)
+	if ge, ok := gitlab.AsGitlabError(err); ok && ge.IsNotFound() {
+		log.Ctx(ctx).Warn().
+			Int64("iid", iid).
+			Msg("issue not found")
+
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// dry run returns empty issue
+	if is.IID == 0 {
+		is.IID = iid
+	}
+
+	ta[TmpVarIssueTitle] = is.Title
+	ta[TmpVarIssueLabels] = strings.Join(is.Labels, ",")
+	ta[TmpVarIssueURL] = is.URL
+	if is.Milestone != nil {
+		ta[TmpVarIssueMilestone] = is.Milestone.Title
+	}
+
+	return &is, nil
+}
+
+// closeIssue appends "Closes #N" to description if enabled.
+func closeIssue(params IssueParams, is *gitlab.Issue, d string) string {
+	if is == nil || !params.Close {
+		return d
+	}
+
+	cl := fmt.Sprintf("Closes #%d", is.IID)
+	if strings.Contains(d, cl) {
+		return d
+	}
+
+	return d + "\n\n" + cl
+}
+
+// applyIssue copies labels and milestone of issue to MR request.
+func applyIssue(params IssueParams, is *gitlab.Issue, req *gitlab.CreateMRRequest) {
+	if is == nil {
+		return
+	}
+
+	if params.CopyLabels {
+		req.Labels = mergeLabels(req.Labels, is.Labels)
+	}
+
+	if params.CopyMilestone && is.Milestone != nil {
+		req.MilestoneID = is.Milestone.ID
+	}
+}
+
+// mergeLabels adds labels missing in comma separated list.
+func mergeLabels(list string, labels []string) string {
+	var ls []string
+	if list != "" {
+		ls = strings.Split(list, ",")
+	}
+
+	for _, l := range labels {
+		if !containsFold(ls, l) {
+			ls = append(ls, l)
+		}
+	}
+
+	return strings.Join(ls, ",")
+}
@@ -0,0 +1,94 @@
+package glmt
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	hooksi "gitlab.com/gitlab-merge-tool/glmt/internal/hooks/impl"
+)
+
+// issueGitLab has an issue branch is created for.
+type issueGitLab struct {
+	gitlabStub
+	issue gitlab.Issue
+}
+
+func (ig *issueGitLab) Issue(ctx context.Context, project string, iid int64) (gitlab.Issue, error) {
+	ig.f("Issue", iid)
+	return ig.issue, nil
+}
+
+func TestCreateMR_Issue(t *testing.T) {
+	gs := &gitStub{
+		r: testRemote,
+		b: "feature/42-some-fix",
+	}
+
+	var (
+		issueIID int64
+		req      gitlab.CreateMRRequest
+	)
+	gls := &issueGitLab{
+		gitlabStub: gitlabStub{
+			f: func(method string, arg interface{}) {
+				switch method {
+				case "Issue":
+					issueIID = arg.(int64)
+				case "CreateMR":
+					req = arg.(gitlab.CreateMRRequest)
+				}
+			},
+		},
+		issue: gitlab.Issue{
+			IID:       42,
+			Title:     "Fix crash on start",
+			Labels:    []string{"bug", "Feature"},
+			Milestone: &gitlab.Milestone{ID: 7, Title: "v1.2"},
+			URL:       "https://gitlab.com/hummerd/client_golang/-/issues/42",
+		},
+	}
+
+	c := Core{
+		git:    gs,
+		gitLab: gls,
+		hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+	}
+
+	_, err := c.CreateMR(context.Background(), CreateMRParams{
+		TargetBranch:        "master",
+		BranchRegexp:        regexp.MustCompile(`(?P<TaskType>.*)/(?P<Task>\d+)-(?P<BranchDescription>.*)`),
+		DescriptionTemplate: "{{.IssueURL}} {{.IssueMilestone}} {{.IssueLabels}}",
+		LabelVars:           []string{"TaskType"},
+		Issue: IssueParams{
+			Var:           "Task",
+			Close:         true,
+			CopyLabels:    true,
+			CopyMilestone: true,
+		},
+	})
+	if err != nil {
+		t.Fatal("error creating MR", err)
+	}
+
+	if issueIID != 42 {
+		t.Fatal("wrong issue requested", issueIID)
+	}
+
+	exp := gitlab.CreateMRRequest{
+		Project:      "hummerd/client_golang",
+		SourceBranch: gs.b,
+		TargetBranch: "master",
+		Title:        "Fix crash on start",
+		Description:  gls.issue.URL + " v1.2 bug,Feature\n\nCloses #42",
+		AssigneeID:   123,
+		Labels:       "feature,bug",
+		MilestoneID:  7,
+	}
+	if !reflect.DeepEqual(req, exp) {
+		t.Fatalf("expected create request: %+v, got %+v", exp, req)
+	}
+}
@@ -0,0 +1,84 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gerr"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/jira"
+)
+
+const (
+	TmpVarJiraKey     = "JiraKey"
+	TmpVarJiraSummary = "JiraSummary"
+	TmpVarJiraType    = "JiraType"
+	TmpVarJiraStatus  = "JiraStatus"
+	TmpVarJiraURL     = "JiraURL"
+)
+
+var ErrJiraTransition = errors.New("jira transition error")
+
+// JiraParams configures Jira issue referenced by current branch.
+type JiraParams struct {
+	// Var is a name of branch regexp group with issue key, empty disables Jira.
+	Var string
+	// TransitionTo is a status issue is moved to after MR creation.
+	TransitionTo string
+}
+
+// addJiraArgs fetches Jira issue referenced by branch and adds its variables
+// to text args. Nil is returned if branch has no issue key.
+func (c *Core) addJiraArgs(ctx context.Context, params JiraParams, ta map[string]interface{}) (*jira.Issue, error) {
+	ta[TmpVarJiraKey] = ""
+	ta[TmpVarJiraSummary] = ""
+	ta[TmpVarJiraType] = ""
+	ta[TmpVarJiraStatus] = ""
+	ta[TmpVarJiraURL] = ""
+
+	if c.jira == nil || params.Var == "" {
+		return nil, nil
+	}
+
+	key, _ := ta[params.Var].(string)
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if key == "" {
+		return nil, nil
+	}
+
+	is, err := c.jira.Issue(ctx, key)
+	if errors.Is(err, jira.ErrNotFound) {
+		log.Ctx(ctx).Warn().
+			Str("key", key).
+			Msg("jira issue not found")
+
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ta[TmpVarJiraKey] = is.Key
+	ta[TmpVarJiraSummary] = is.Summary
+	ta[TmpVarJiraType] = is.Type
+	ta[TmpVarJiraStatus] = is.Status
+	ta[TmpVarJiraURL] = is.URL
+
+	return &is, nil
+}
+
+// transitionJira moves issue to configured status if it is not there yet.
+func (c *Core) transitionJira(ctx context.Context, params JiraParams, is *jira.Issue) error {
+	if is == nil || params.TransitionTo == "" || strings.EqualFold(is.Status, params.TransitionTo) {
+		return nil
+	}
+
+	err := c.jira.Transition(ctx, is.Key, params.TransitionTo)
+	if err != nil {
+		return gerr.NewNestedError(ErrJiraTransition, err)
+	}
+
+	return nil
+}
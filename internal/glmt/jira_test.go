@@ -0,0 +1,106 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	hooksi "gitlab.com/gitlab-merge-tool/glmt/internal/hooks/impl"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/jira"
+)
+
+func TestCreateMR_Jira(t *testing.T) {
+	gs := &gitStub{
+		r: testRemote,
+		b: "feature/proj-123/short-name",
+	}
+
+	var req gitlab.CreateMRRequest
+	gls := &gitlabStub{
+		f: func(method string, arg interface{}) {
+			if method == "CreateMR" {
+				req = arg.(gitlab.CreateMRRequest)
+			}
+		},
+	}
+
+	js := &jiraStub{
+		issue: jira.Issue{
+			Key:     "PROJ-123",
+			Summary: "Short name",
+			Type:    "Story",
+			Status:  "In Progress",
+			URL:     "https://jira.example.com/browse/PROJ-123",
+		},
+	}
+
+	c := Core{
+		git:    gs,
+		gitLab: gls,
+		hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+		jira:   js,
+	}
+
+	cp := CreateMRParams{
+		TargetBranch:        "master",
+		BranchRegexp:        regexp.MustCompile(`(?P<TaskType>.*)/(?P<Task>.*)/(?P<BranchDescription>.*)`),
+		DescriptionTemplate: "{{.JiraType}} [{{.JiraKey}}]({{.JiraURL}}) {{.JiraStatus}}",
+		Jira: JiraParams{
+			Var:          "Task",
+			TransitionTo: "In Review",
+		},
+	}
+
+	_, err := c.CreateMR(context.Background(), cp)
+	if err != nil {
+		t.Fatal("error creating MR", err)
+	}
+
+	if req.Title != "Short name" {
+		t.Fatal("title should be jira summary, got", req.Title)
+	}
+
+	exp := "Story [PROJ-123](https://jira.example.com/browse/PROJ-123) In Progress"
+	if req.Description != exp {
+		t.Fatalf("exp description: %q, got: %q", exp, req.Description)
+	}
+
+	if js.key != "PROJ-123" || js.status != "In Review" {
+		t.Fatal("issue is not moved", js.key, js.status)
+	}
+
+	js.err = errors.New("no permissions")
+	mr, err := c.CreateMR(context.Background(), cp)
+	if !errors.Is(err, ErrJiraTransition) {
+		t.Fatal("expected ErrJiraTransition, got", err)
+	}
+
+	if mr.ID == 0 {
+		t.Fatal("MR should be returned with transition error")
+	}
+}
+
+type jiraStub struct {
+	issue jira.Issue
+	err   error
+	// key and status of last transition
+	key    string
+	status string
+}
+
+func (js *jiraStub) Issue(ctx context.Context, key string) (jira.Issue, error) {
+	if key != js.issue.Key {
+		return jira.Issue{}, jira.ErrNotFound
+	}
+
+	return js.issue, nil
+}
+
+func (js *jiraStub) Transition(ctx context.Context, key, status string) error {
+	js.key = key
+	js.status = status
+	return js.err
+}
@@ -0,0 +1,155 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrUnknownLabels = errors.New("unknown labels")
+
+type LabelsParams struct {
+	// Static labels are added to every MR.
+	Static []string
+	// Map maps values of template variables to labels, variables from
+	// map are used as LabelVars.
+	Map         map[string]map[string]string
+	BranchRules []LabelRule
+	// Add and Remove are labels added and removed by user.
+	Add    []string
+	Remove []string
+	// Validate checks labels against project's labels, unknown labels
+	// are reported as warnings.
+	Validate bool
+	// Strict fails with ErrUnknownLabels on unknown labels.
+	Strict bool
+}
+
+// LabelRule adds labels if branch matches regexp.
+type LabelRule struct {
+	Regexp *regexp.Regexp
+	Labels []string
+}
+
+// labelsFrom returns comma separated labels from static labels, template
+// variables and branch rules.
+func labelsFrom(ta map[string]interface{}, labelVars []string, params LabelsParams, branch string) string {
+	labels := make([]string, 0, len(params.Static)+len(labelVars))
+	labels = append(labels, params.Static...)
+
+	vars := append([]string{}, labelVars...)
+	mapped := make([]string, 0, len(params.Map))
+	for v := range params.Map {
+		if !containsFold(vars, v) {
+			mapped = append(mapped, v)
+		}
+	}
+
+	sort.Strings(mapped)
+	vars = append(vars, mapped...)
+
+	for _, lv := range vars {
+		val, ok := ta[lv].(string)
+		if !ok || val == "" {
+			continue
+		}
+
+		l, ok := mapLabel(params.Map[lv], val)
+		if !ok && !containsFold(labelVars, lv) {
+			// variable is only in map, so unmapped value is not a label
+			continue
+		}
+
+		labels = append(labels, l)
+	}
+
+	for _, r := range params.BranchRules {
+		if r.Regexp != nil && r.Regexp.MatchString(branch) {
+			labels = append(labels, r.Labels...)
+		}
+	}
+
+	return mergeLabels("", labels)
+}
+
+// mapLabel returns label mapped from value, value itself is returned if it is not mapped.
+func mapLabel(m map[string]string, val string) (string, bool) {
+	if l, ok := m[val]; ok {
+		return l, true
+	}
+
+	for k, l := range m {
+		if strings.EqualFold(k, val) {
+			return l, true
+		}
+	}
+
+	return val, false
+}
+
+// changeLabels adds and removes labels requested by user.
+func changeLabels(params LabelsParams, list string) string {
+	list = mergeLabels(list, params.Add)
+	if len(params.Remove) == 0 {
+		return list
+	}
+
+	var ls []string
+	for _, l := range splitList(list) {
+		if !containsFold(params.Remove, l) {
+			ls = append(ls, l)
+		}
+	}
+
+	return strings.Join(ls, ",")
+}
+
+// validateLabels checks labels against project's labels. Case of known labels
+// is fixed to case of project's labels.
+func (c *Core) validateLabels(ctx context.Context, project string, params LabelsParams, list string) (string, error) {
+	if (!params.Validate && !params.Strict) || list == "" {
+		return list, nil
+	}
+
+	pls, err := c.gitLab.ProjectLabels(ctx, project)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		ls      = splitList(list)
+		unknown []string
+	)
+
+	for i, l := range ls {
+		found := false
+		for _, pl := range pls {
+			if strings.EqualFold(pl.Name, l) {
+				ls[i] = pl.Name
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			unknown = append(unknown, l)
+		}
+	}
+
+	if len(unknown) > 0 {
+		if params.Strict {
+			return "", fmt.Errorf("%w: %s", ErrUnknownLabels, strings.Join(unknown, ", "))
+		}
+
+		log.Ctx(ctx).Warn().
+			Strs("labels", unknown).
+			Msg("labels are missing in project and will be created")
+	}
+
+	return strings.Join(ls, ","), nil
+}
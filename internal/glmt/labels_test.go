@@ -0,0 +1,111 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	hooksi "gitlab.com/gitlab-merge-tool/glmt/internal/hooks/impl"
+)
+
+func TestLabelsFrom(t *testing.T) {
+	ta := map[string]interface{}{
+		"TaskType": "feat",
+		"Scope":    "api",
+		"Task":     "123",
+	}
+
+	params := LabelsParams{
+		Static: []string{"glmt"},
+		Map: map[string]map[string]string{
+			"TaskType": {"FEAT": "type::feature"},
+			"Task":     {"999": "special"},
+		},
+		BranchRules: []LabelRule{{
+			Regexp: regexp.MustCompile(`^feat/`),
+			Labels: []string{"review::needed"},
+		}, {
+			Regexp: regexp.MustCompile(`^hotfix/`),
+			Labels: []string{"priority::high"},
+		}},
+	}
+
+	ls := labelsFrom(ta, []string{"TaskType", "Scope"}, params, "feat/api/123")
+	exp := "glmt,type::feature,api,review::needed"
+	if ls != exp {
+		t.Fatalf("exp labels: %q, got: %q", exp, ls)
+	}
+
+	params.Add = []string{"extra", "API"}
+	params.Remove = []string{"GLMT"}
+	ls = changeLabels(params, ls)
+	exp = "type::feature,api,review::needed,extra"
+	if ls != exp {
+		t.Fatalf("exp changed labels: %q, got: %q", exp, ls)
+	}
+}
+
+// labelsGitLab has project labels.
+type labelsGitLab struct {
+	gitlabStub
+	labels []gitlab.Label
+}
+
+func (lg *labelsGitLab) ProjectLabels(ctx context.Context, project string) ([]gitlab.Label, error) {
+	lg.f("ProjectLabels", project)
+	return lg.labels, nil
+}
+
+func TestCreateMR_ValidateLabels(t *testing.T) {
+	var req gitlab.CreateMRRequest
+	gls := &labelsGitLab{
+		gitlabStub: gitlabStub{
+			f: func(method string, arg interface{}) {
+				if method == "CreateMR" {
+					req = arg.(gitlab.CreateMRRequest)
+				}
+			},
+		},
+		labels: []gitlab.Label{{Name: "Bug"}, {Name: "type::feature"}},
+	}
+
+	c := Core{
+		git: &gitStub{
+			r: testRemote,
+			b: "feature",
+		},
+		gitLab: gls,
+		hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+	}
+
+	params := CreateMRParams{
+		TargetBranch: "master",
+		Labels: LabelsParams{
+			Add:      []string{"bug", "tpye::feature"},
+			Validate: true,
+		},
+	}
+
+	_, err := c.CreateMR(context.Background(), params)
+	if err != nil {
+		t.Fatal("error creating MR", err)
+	}
+
+	if req.Labels != "Bug,tpye::feature" {
+		t.Fatal("unexpected labels", req.Labels)
+	}
+
+	req = gitlab.CreateMRRequest{}
+	params.Labels.Strict = true
+	_, err = c.CreateMR(context.Background(), params)
+	if !errors.Is(err, ErrUnknownLabels) {
+		t.Fatal("expected ErrUnknownLabels, got", err)
+	}
+
+	if req.Labels != "" {
+		t.Fatal("MR with unknown labels should not be created")
+	}
+}
@@ -1,12 +1,28 @@
 package glmt
 
 import (
+	"context"
+	"errors"
 	"math/rand"
 	"strings"
 
+	"github.com/rs/zerolog/log"
+
 	"gitlab.com/gitlab-merge-tool/glmt/internal/team"
 )
 
+// Modes of using mentioned members in MR.
+const (
+	// MentionModeMention only mentions members in description (default).
+	MentionModeMention = "mention"
+	// MentionModeReviewers sets members as MR's reviewers.
+	MentionModeReviewers = "reviewers"
+	// MentionModeAssignees sets members as MR's assignees along with its author.
+	MentionModeAssignees = "assignees"
+	// MentionModeBoth sets members as reviewers and assignees.
+	MentionModeBoth = "both"
+)
+
 // Mentions selects users to be mentioned in MR. Sekects project owner (if there is one)
 // plus additional random members.
 func Mentions(t *team.Team, me, project string, count int) []*team.Member {
@@ -54,3 +70,56 @@ func Mentions(t *team.Team, me, project string, count int) []*team.Member {
 
 	return selectedMembers
 }
+
+// mentionIDs resolves GitLab user IDs of members to be set as MR's reviewers
+// and assignees according to mode. Members unknown to GitLab are skipped.
+// Assignees replace the author assigned by default, so author goes first of them.
+func (c *Core) mentionIDs(
+	ctx context.Context,
+	mode string,
+	author int,
+	members []*team.Member,
+) (reviewers, assignees []int, err error) {
+	switch mode {
+	case "", MentionModeMention:
+		return nil, nil, nil
+	case MentionModeReviewers, MentionModeAssignees, MentionModeBoth:
+	default:
+		return nil, nil, errors.New("unknown mention mode: " + mode)
+	}
+
+	ids := make([]int, 0, len(members))
+	for _, m := range members {
+		u, err := c.gitLab.UserByUsername(ctx, m.Username)
+		if err != nil {
+			log.Ctx(ctx).Warn().
+				Err(err).
+				Str("username", m.Username).
+				Msg("can not find team member in gitlab")
+			continue
+		}
+
+		if u.ID != 0 {
+			ids = append(ids, u.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil, nil
+	}
+
+	if mode == MentionModeReviewers || mode == MentionModeBoth {
+		reviewers = ids
+	}
+
+	if mode == MentionModeAssignees || mode == MentionModeBoth {
+		assignees = []int{author}
+		for _, id := range ids {
+			if id != author {
+				assignees = append(assignees, id)
+			}
+		}
+	}
+
+	return reviewers, assignees, nil
+}
@@ -1,9 +1,13 @@
 package glmt
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	hooksi "gitlab.com/gitlab-merge-tool/glmt/internal/hooks/impl"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/team"
 )
 
@@ -41,3 +45,89 @@ func TestMentioner(t *testing.T) {
 		t.Fatalf("exp: %v, got: %v", expMembers, ms)
 	}
 }
+
+func TestMentionIDs(t *testing.T) {
+	gls := &gitlabStub{
+		f: func(string, interface{}) {},
+		users: map[string]int{
+			"billi":   1,
+			"william": 3,
+		},
+	}
+
+	c := Core{
+		gitLab: gls,
+	}
+
+	members := []*team.Member{
+		{Username: "billi"},
+		{Username: "unknown"},
+		{Username: "william"},
+	}
+
+	reviewers, assignees, err := c.mentionIDs(context.Background(), MentionModeReviewers, 7, members)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual([]int{1, 3}, reviewers) || assignees != nil {
+		t.Fatalf("wrong reviewers: %v, assignees: %v", reviewers, assignees)
+	}
+
+	reviewers, assignees, err = c.mentionIDs(context.Background(), MentionModeBoth, 7, members)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// author stays assigned
+	if !reflect.DeepEqual([]int{1, 3}, reviewers) || !reflect.DeepEqual([]int{7, 1, 3}, assignees) {
+		t.Fatalf("wrong reviewers: %v, assignees: %v", reviewers, assignees)
+	}
+
+	reviewers, assignees, err = c.mentionIDs(context.Background(), MentionModeMention, 7, members)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reviewers != nil || assignees != nil {
+		t.Fatalf("wrong reviewers: %v, assignees: %v", reviewers, assignees)
+	}
+}
+
+func TestCreateMR_AssigneesKeepAuthor(t *testing.T) {
+	var req gitlab.CreateMRRequest
+	gls := &gitlabStub{
+		f: func(method string, arg interface{}) {
+			if method == "CreateMR" {
+				req = arg.(gitlab.CreateMRRequest)
+			}
+		},
+		users: map[string]int{"jane": 12},
+	}
+
+	c := Core{
+		git: &gitStub{
+			r: testRemote,
+			b: "feature",
+		},
+		gitLab: gls,
+		hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+		teamSource: &teamStub{tm: &team.Team{
+			Members: []*team.Member{{Username: "jane", IsActive: true}},
+		}},
+	}
+
+	_, err := c.CreateMR(context.Background(), CreateMRParams{
+		TargetBranch:  "master",
+		MentionsCount: 1,
+		MentionMode:   MentionModeAssignees,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// gitlabStub's current user is 123
+	if req.AssigneeID != 123 || !reflect.DeepEqual(req.AssigneeIDs, []int{123, 12}) {
+		t.Fatalf("exp author and jane assigned, got: %d, %v", req.AssigneeID, req.AssigneeIDs)
+	}
+}
@@ -0,0 +1,162 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/templating"
+)
+
+var (
+	ErrNotMergeable = errors.New("merge request can not be merged")
+)
+
+// Statuses of MR returned by Merge.
+const (
+	MRStatusMerged = "merged"
+	// MRStatusMergeScheduled means MR will be merged when pipeline succeeds.
+	MRStatusMergeScheduled = "merge_scheduled"
+)
+
+type MergeParams struct {
+	// TargetBranch is used to choose MR if there are several MRs for current branch.
+	TargetBranch string
+	BranchRegexp *regexp.Regexp
+	// WhenPipelineSucceeds sets MR to be merged when pipeline succeeds
+	// instead of merging it now.
+	WhenPipelineSucceeds bool
+	// SquashCommitTemplate and MergeCommitTemplate are templates of commit messages,
+	// if empty GitLab's default messages are used.
+	SquashCommitTemplate string
+	MergeCommitTemplate  string
+	Squash               bool
+	RemoveBranch         bool
+	// Force skips checks of conflicts, discussions and approvals.
+	Force       bool
+	IgnoreHooks bool
+	// LenientTemplates renders unknown variables empty and ignores errors of templates.
+	LenientTemplates bool
+}
+
+// Merge merges MR of current branch.
+func (c *Core) Merge(ctx context.Context, params MergeParams) (MergeRequest, error) {
+	var mr MergeRequest
+
+	ri, err := c.repoInfo()
+	if err != nil {
+		return mr, err
+	}
+
+	gmr, err := c.branchMR(ctx, ri, params.TargetBranch)
+	if err != nil {
+		return mr, err
+	}
+
+	if !params.Force {
+		err = c.checkMergeable(ctx, ri.project, gmr)
+		if err != nil {
+			return mr, err
+		}
+	}
+
+	cu, err := c.gitLab.CurrentUser(ctx)
+	if err != nil {
+		return mr, err
+	}
+
+	ta := getTextArgs(ri, cu.Username, CreateMRParams{
+		TargetBranch: gmr.TargetBranch,
+		BranchRegexp: params.BranchRegexp,
+	}, nil)
+	ta[TmpVarTitle] = gmr.Title
+	ta[TmpVarDescription] = gmr.Description
+	ta[TmpVarMRURL] = gmr.URL
+	ta[TmpVarMRChangesCount] = gmr.ChangesCount
+
+	req := gitlab.MergeMRRequest{
+		Project:                   ri.project,
+		IID:                       gmr.IID,
+		Squash:                    params.Squash,
+		ShouldRemoveSourceBranch:  params.RemoveBranch,
+		MergeWhenPipelineSucceeds: params.WhenPipelineSucceeds,
+		SHA:                       gmr.SHA,
+	}
+
+	sm, err := templating.CreateText("squash_commit_message", params.SquashCommitTemplate, templateArgs(ta), params.LenientTemplates)
+	if err != nil {
+		return mr, err
+	}
+
+	mm, err := templating.CreateText("merge_commit_message", params.MergeCommitTemplate, templateArgs(ta), params.LenientTemplates)
+	if err != nil {
+		return mr, err
+	}
+
+	req.SquashCommitMessage = strings.TrimSpace(sm)
+	req.MergeCommitMessage = strings.TrimSpace(mm)
+
+	if !params.IgnoreHooks {
+		err = c.hooks.RunMerge(ctx, hookParams(ta))
+		if err != nil {
+			return mr, fmt.Errorf("merge hooks failed: %w", err)
+		}
+	}
+
+	log.Ctx(ctx).Debug().
+		Interface("context", ta).
+		Interface("request", req).
+		Msg("merge mr")
+
+	gmr, err = c.gitLab.MergeMR(ctx, req)
+	if err != nil {
+		return mr, err
+	}
+
+	mr.ID = gmr.ID
+	mr.IID = gmr.IID
+	mr.ProjectID = gmr.ProjectID
+	mr.CreatedAt = gmr.CreatedAt
+	mr.URL = gmr.URL
+	mr.ChangesCount = gmr.ChangesCount
+	mr.Status = MRStatusMerged
+	if gmr.State != "merged" && params.WhenPipelineSucceeds {
+		mr.Status = MRStatusMergeScheduled
+	}
+
+	return mr, nil
+}
+
+// checkMergeable checks conflicts, unresolved discussions and approvals.
+// All found problems are reported in a single error.
+func (c *Core) checkMergeable(ctx context.Context, project string, mr gitlab.MergeRequest) error {
+	var problems []string
+
+	if mr.HasConflicts {
+		problems = append(problems, "has conflicts with "+mr.TargetBranch)
+	}
+
+	if !mr.BlockingDiscussionsResolved {
+		problems = append(problems, "has unresolved discussions")
+	}
+
+	a, err := c.gitLab.MRApprovals(ctx, project, mr.IID)
+	if err != nil {
+		return err
+	}
+
+	if a.ApprovalsLeft > 0 {
+		problems = append(problems, fmt.Sprintf("needs %d more approval(s)", a.ApprovalsLeft))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrNotMergeable, strings.Join(problems, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	hooksi "gitlab.com/gitlab-merge-tool/glmt/internal/hooks/impl"
+)
+
+// mergeGitLab merges MRs.
+type mergeGitLab struct {
+	mrStateGitLab
+}
+
+func (mg *mergeGitLab) MergeMR(ctx context.Context, req gitlab.MergeMRRequest) (gitlab.MergeRequest, error) {
+	mg.f("MergeMR", req)
+	return gitlab.MergeRequest{
+		IID:   req.IID,
+		State: "merged",
+		URL:   mg.mr.URL,
+	}, nil
+}
+
+func TestMerge(t *testing.T) {
+	gs := &gitStub{
+		r: testRemote,
+		b: "feature/TASK-123/add-some-feature",
+	}
+
+	var merged bool
+	gls := &mergeGitLab{mrStateGitLab{gitlabStub: gitlabStub{
+		f: func(method string, arg interface{}) {
+			if method == "MergeMR" {
+				merged = true
+
+				exp := gitlab.MergeMRRequest{
+					Project:             "hummerd/client_golang",
+					IID:                 7,
+					SquashCommitMessage: "TASK-123 Add some feature (feature)",
+					Squash:              true,
+					SHA:                 "abc",
+				}
+
+				if exp != arg.(gitlab.MergeMRRequest) {
+					t.Fatalf("expected merge request: %+v, got %+v", exp, arg)
+				}
+			}
+		},
+		mrs: []gitlab.MergeRequest{{IID: 7}},
+		mr: gitlab.MergeRequest{
+			IID:                         7,
+			Title:                       "TASK-123 Add some feature",
+			SHA:                         "abc",
+			TargetBranch:                "develop",
+			BlockingDiscussionsResolved: true,
+		},
+	}}}
+
+	c := Core{
+		git:    gs,
+		gitLab: gls,
+		hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+	}
+
+	params := MergeParams{
+		BranchRegexp:         regexp.MustCompile("(?P<TaskType>.*)/(?P<Task>.*)/(?P<BranchDescription>.*)"),
+		SquashCommitTemplate: "{{.Title}} ({{.TaskType}})",
+		Squash:               true,
+	}
+
+	mr, err := c.Merge(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !merged || mr.Status != MRStatusMerged {
+		t.Fatal("MR is not merged")
+	}
+
+	merged = false
+	gls.mr.HasConflicts = true
+	gls.approvals.ApprovalsLeft = 1
+
+	_, err = c.Merge(context.Background(), params)
+	if !errors.Is(err, ErrNotMergeable) {
+		t.Fatal("expected ErrNotMergeable, got", err)
+	}
+
+	if merged {
+		t.Fatal("MR should not be merged")
+	}
+}
@@ -0,0 +1,177 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gerr"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/team"
+)
+
+var (
+	ErrNoMR        = errors.New("merge request not found")
+	ErrAmbiguousMR = errors.New("several merge requests found")
+)
+
+const draftPrefix = "Draft: "
+
+// mentionRegExp matches GitLab mentions of users.
+var mentionRegExp = regexp.MustCompile(`(?:^|[^\w@])@([\w\-]+(?:\.[\w\-]+)*)`)
+
+// draftRegExp matches title prefixes GitLab treats as draft.
+var draftRegExp = regexp.MustCompile(`(?i)^\s*(\[draft\]|\(draft\)|draft:|draft\s+-|\[wip\]|wip:|wip\s+-)\s*`)
+
+type ReadyParams struct {
+	// TargetBranch is used to choose MR if there are several MRs for current branch.
+	TargetBranch        string
+	BranchRegexp        *regexp.Regexp
+	NotificationMessage string
+}
+
+// Ready removes draft status from MR of current branch and sends notifications.
+// Notifications are not sent if MR is already ready.
+func (c *Core) Ready(ctx context.Context, params ReadyParams) (MergeRequest, error) {
+	var mr MergeRequest
+
+	ri, err := c.repoInfo()
+	if err != nil {
+		return mr, err
+	}
+
+	gmr, err := c.branchMR(ctx, ri, params.TargetBranch)
+	if err != nil {
+		return mr, err
+	}
+
+	mr.ID = gmr.ID
+	mr.IID = gmr.IID
+	mr.ProjectID = gmr.ProjectID
+	mr.CreatedAt = gmr.CreatedAt
+	mr.URL = gmr.URL
+	mr.ChangesCount = gmr.ChangesCount
+
+	if !isDraftMR(gmr) {
+		mr.Status = MRStatusExisting
+		return mr, nil
+	}
+
+	gmr, err = c.gitLab.UpdateMR(ctx, gitlab.UpdateMRRequest{
+		Project: ri.project,
+		IID:     gmr.IID,
+		Title:   trimDraft(gmr.Title),
+	})
+	if err != nil {
+		return mr, err
+	}
+
+	mr.Status = MRStatusUpdated
+	mr.URL = gmr.URL
+	mr.ChangesCount = gmr.ChangesCount
+
+	if c.notifier == nil {
+		return mr, nil
+	}
+
+	cu, err := c.gitLab.CurrentUser(ctx)
+	if err != nil {
+		return mr, err
+	}
+
+	var ms []*team.Member
+	if c.teamSource != nil {
+		tm, err := c.teamSource.Team(ctx)
+		if err != nil {
+			return mr, err
+		}
+
+		// members are not chosen again, so the same members are notified
+		ms = mrMembers(tm, gmr)
+	}
+
+	ta := getTextArgs(ri, cu.Username, CreateMRParams{
+		TargetBranch: gmr.TargetBranch,
+		BranchRegexp: params.BranchRegexp,
+	}, ms)
+	ta[TmpVarTitle] = gmr.Title
+	ta[TmpVarDescription] = gmr.Description
+	ta[TmpVarMRURL] = gmr.URL
+	ta[TmpVarMRChangesCount] = gmr.ChangesCount
+
+	err = c.notifier.Send(ctx, templateArgs(ta), params.NotificationMessage, ms)
+	if err != nil {
+		err = gerr.NewNestedError(ErrNotification, err)
+	}
+
+	log.Ctx(ctx).Debug().
+		Interface("context", ta).
+		Msg("notification")
+
+	return mr, err
+}
+
+// branchMR finds opened MR from current branch. If target is empty and there are
+// several MRs ErrAmbiguousMR is returned.
+func (c *Core) branchMR(ctx context.Context, ri repoInfo, target string) (gitlab.MergeRequest, error) {
+	mrs, err := c.branchMRs(ctx, ri, target)
+	if err != nil {
+		return gitlab.MergeRequest{}, err
+	}
+
+	switch len(mrs) {
+	case 0:
+		return gitlab.MergeRequest{}, fmt.Errorf("%w: no opened MR from %s", ErrNoMR, ri.branch)
+	case 1:
+	default:
+		return gitlab.MergeRequest{}, fmt.Errorf("%w: specify target branch", ErrAmbiguousMR)
+	}
+
+	// single MR request contains more details
+	return c.gitLab.GetMR(ctx, ri.project, mrs[0].IID)
+}
+
+// mrMembers returns team members who are MR's reviewers or assignees or are
+// mentioned in MR's description.
+func mrMembers(tm *team.Team, mr gitlab.MergeRequest) []*team.Member {
+	if tm == nil {
+		return nil
+	}
+
+	usernames := make([]string, 0, len(mr.Reviewers)+len(mr.Assignees))
+	for _, u := range mr.Reviewers {
+		usernames = append(usernames, u.Username)
+	}
+	for _, u := range mr.Assignees {
+		usernames = append(usernames, u.Username)
+	}
+	for _, m := range mentionRegExp.FindAllStringSubmatch(mr.Description, -1) {
+		usernames = append(usernames, m[1])
+	}
+
+	var ms []*team.Member
+	for _, m := range tm.Members {
+		if !strings.EqualFold(m.Username, mr.Author.Username) && containsFold(usernames, m.Username) {
+			ms = append(ms, m)
+		}
+	}
+
+	return ms
+}
+
+// isDraftMR reports if MR is draft by GitLab's flags or by title.
+func isDraftMR(mr gitlab.MergeRequest) bool {
+	return mr.Draft || mr.WorkInProgress || isDraftTitle(mr.Title)
+}
+
+func isDraftTitle(title string) bool {
+	return draftRegExp.MatchString(title)
+}
+
+func trimDraft(title string) string {
+	return draftRegExp.ReplaceAllString(title, "")
+}
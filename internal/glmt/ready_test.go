@@ -0,0 +1,153 @@
+package glmt
+
+import (
+	"context"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/notifier"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/team"
+)
+
+func TestTrimDraft(t *testing.T) {
+	titles := map[string]string{
+		"Draft: TASK-1 Feature":   "TASK-1 Feature",
+		"[Draft] TASK-1 Feature":  "TASK-1 Feature",
+		"(draft) TASK-1 Feature":  "TASK-1 Feature",
+		"WIP: TASK-1 Feature":     "TASK-1 Feature",
+		"TASK-1 Draft: Feature":   "TASK-1 Draft: Feature",
+		"TASK-1 Drafting feature": "TASK-1 Drafting feature",
+	}
+
+	for title, exp := range titles {
+		if got := trimDraft(title); got != exp {
+			t.Fatalf("exp: %q, got: %q", exp, got)
+		}
+	}
+}
+
+func TestReady(t *testing.T) {
+	cases := []struct {
+		name  string
+		mr    gitlab.MergeRequest
+		ready bool
+	}{
+		{"draft title", gitlab.MergeRequest{Title: "Draft: TASK-123 Add some feature"}, false},
+		{"draft flag", gitlab.MergeRequest{Title: "TASK-123 Add some feature", Draft: true}, false},
+		{"wip flag", gitlab.MergeRequest{Title: "TASK-123 Add some feature", WorkInProgress: true}, false},
+		{"ready", gitlab.MergeRequest{Title: "TASK-123 Add some feature"}, true},
+	}
+
+	for _, tc := range cases {
+		var updated bool
+		gls := &gitlabStub{
+			f: func(method string, arg interface{}) {
+				if method == "UpdateMR" {
+					updated = true
+
+					req := arg.(gitlab.UpdateMRRequest)
+					if req.Title != "TASK-123 Add some feature" || req.IID != 7 {
+						t.Fatalf("%s: wrong update request: %+v", tc.name, req)
+					}
+				}
+			},
+			mrs: []gitlab.MergeRequest{{IID: 7}},
+			mr:  tc.mr,
+		}
+		gls.mr.IID = 7
+		gls.mr.URL = "https://gitlab.com/hummerd/client_golang/-/merge_requests/7"
+
+		ns := &notifierStub{}
+		c := Core{
+			git: &gitStub{
+				r: testRemote,
+				b: "feature/TASK-123/add-some-feature",
+			},
+			gitLab:   gls,
+			notifier: ns,
+		}
+
+		mr, err := c.Ready(context.Background(), ReadyParams{})
+		if err != nil {
+			t.Fatal(tc.name, err)
+		}
+
+		if tc.ready {
+			if updated || mr.Status != MRStatusExisting || ns.args != nil {
+				t.Fatalf("%s: ready MR is updated or notified: %+v", tc.name, mr)
+			}
+			continue
+		}
+
+		if !updated || mr.Status != MRStatusUpdated {
+			t.Fatalf("%s: MR is not updated", tc.name)
+		}
+
+		if ns.args[TmpVarMRURL] != gls.mr.URL {
+			t.Fatalf("%s: notification is not sent: %v", tc.name, ns.args)
+		}
+	}
+}
+
+// teamStub returns the same team.
+type teamStub struct {
+	tm *team.Team
+}
+
+func (ts *teamStub) Team(ctx context.Context) (*team.Team, error) {
+	return ts.tm, nil
+}
+
+func TestReady_MentionedMembers(t *testing.T) {
+	tm := &team.Team{}
+	for _, u := range []string{"author", "jane", "bob.smith", "alice", "eve"} {
+		tm.Members = append(tm.Members, &team.Member{Username: u, IsActive: true})
+	}
+
+	gls := &gitlabStub{
+		f:   func(string, interface{}) {},
+		mrs: []gitlab.MergeRequest{{IID: 7}},
+		mr: gitlab.MergeRequest{
+			IID:         7,
+			Title:       "Draft: Add feature",
+			Description: "Add feature\n\nReview: @jane, @bob.smith. Email: eve@example.com",
+			Author:      gitlab.UserResponse{Username: "author"},
+		},
+	}
+
+	ns := &notifierStub{}
+	c := Core{
+		git:        &gitStub{r: testRemote, b: "feature"},
+		gitLab:     gls,
+		notifier:   ns,
+		teamSource: &teamStub{tm: tm},
+	}
+
+	// the team is larger than MR's mentions, none of others is chosen instead
+	for i := 0; i < 5; i++ {
+		_, err := c.Ready(context.Background(), ReadyParams{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(ns.mentions) != 2 || ns.mentions[0].Username != "jane" || ns.mentions[1].Username != "bob.smith" {
+			t.Fatalf("exp mentioned members notified, got %v", ns.mentions)
+		}
+	}
+}
+
+type notifierStub struct {
+	args     map[string]interface{}
+	mentions []*team.Member
+}
+
+func (ns *notifierStub) Send(ctx context.Context, args map[string]interface{}, add string, mentions []*team.Member) error {
+	ns.args = args
+	ns.mentions = mentions
+	return nil
+}
+
+func (ns *notifierStub) Render(args map[string]interface{}, add string, mentions []*team.Member) ([]notifier.Message, error) {
+	ns.args = args
+	return []notifier.Message{{Notifier: "stub", Text: add}}, nil
+}
@@ -0,0 +1,110 @@
+package glmt
+
+import (
+	"context"
+	"fmt"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/notifier"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/team"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/templating"
+)
+
+type RenderParams struct {
+	// MR are params of MR creation, MR is rendered as CreateMR would submit it.
+	MR CreateMRParams
+	// Username is used instead of GitLab's current user.
+	Username string
+	// Templates are additional templates rendered with the same variables.
+	Templates []string
+}
+
+// Rendered is a preview of MR's texts and notifications.
+type Rendered struct {
+	Vars          map[string]interface{} `json:"vars"`
+	Title         string                 `json:"title"`
+	Description   string                 `json:"description"`
+	Labels        []string               `json:"labels"`
+	Notifications []notifier.Message     `json:"notifications"`
+	Templates     []RenderedTemplate     `json:"templates"`
+}
+
+type RenderedTemplate struct {
+	Template string `json:"template"`
+	Text     string `json:"text"`
+	// Error is an error of rendering, other templates are rendered anyway.
+	Error string `json:"error,omitempty"`
+}
+
+// Render renders title, description, labels and notifications of current
+// branch's MR the same way CreateMR does, but without sending notifications.
+// Issue and Jira variables are left empty, as they are requested from trackers,
+// so title does not fall back to issue's title. Labels are not validated against
+// project's labels. MR templates are read from GitLab only if checkout has none.
+func (c *Core) Render(ctx context.Context, params RenderParams) (Rendered, error) {
+	var r Rendered
+
+	ri, err := c.repoInfo()
+	if err != nil {
+		return r, err
+	}
+
+	mp := params.MR
+
+	var ms []*team.Member
+	if c.teamSource != nil && mp.MentionsCount > 0 {
+		tm, err := c.teamSource.Team(ctx)
+		if err != nil {
+			return r, err
+		}
+
+		ms = Mentions(tm, params.Username, ri.project, mp.MentionsCount)
+	}
+
+	ta := getTextArgs(ri, params.Username, mp, ms)
+	for k, v := range mp.TextArgs {
+		ta[k] = v
+	}
+
+	cs := c.addCommitArgs(ctx, ri, mp.TargetBranch, ta)
+
+	dt, err := c.descriptionTemplate(ctx, ri, mp)
+	if err != nil {
+		return r, err
+	}
+
+	req, err := newMRRequest(mp, ri.branch, dt, ta, cs, nil, nil)
+	if err != nil {
+		return r, err
+	}
+
+	r.Title = req.Title
+	r.Description = req.Description
+	r.Labels = splitList(req.Labels)
+
+	ta = templateArgs(ta)
+	ta[TmpVarTitle] = r.Title
+	ta[TmpVarDescription] = r.Description
+
+	for i, tmpl := range params.Templates {
+		rt := RenderedTemplate{Template: tmpl}
+
+		rt.Text, err = templating.CreateText(fmt.Sprintf("template_%d", i+1), tmpl, ta, mp.LenientTemplates)
+		if err != nil {
+			rt.Error = err.Error()
+		}
+
+		r.Templates = append(r.Templates, rt)
+	}
+
+	if c.notifier != nil {
+		// notifiers set their mentions to args, so they are rendered with a copy
+		r.Notifications, err = c.notifier.Render(templateArgs(ta), mp.NotificationMessage, ms)
+		if err != nil {
+			return r, fmt.Errorf("can not render notifications: %w", err)
+		}
+	}
+
+	r.Vars = ta
+
+	return r, nil
+}
@@ -0,0 +1,119 @@
+package glmt
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	hooksi "gitlab.com/gitlab-merge-tool/glmt/internal/hooks/impl"
+)
+
+func TestRender(t *testing.T) {
+	ns := &notifierStub{}
+
+	c := Core{
+		git: &gitStub{
+			r:       testRemote,
+			b:       "feature/TASK-123/add-some-feature",
+			commits: []Commit{{Subject: "Add feature"}},
+		},
+		notifier: ns,
+	}
+
+	r, err := c.Render(context.Background(), RenderParams{
+		MR: CreateMRParams{
+			TargetBranch:        "develop",
+			BranchRegexp:        regexp.MustCompile("(?P<TaskType>.*)/(?P<Task>.*)/(?P<BranchDescription>.*)"),
+			DescriptionTemplate: "{{range .Commits}}* {{.Subject}}{{end}}",
+			NotificationMessage: "please review",
+		},
+		Username:  "me",
+		Templates: []string{"{{.TaskType}} by {{.Username}}", "{{.Tsk}}"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Title != "Add feature" || r.Description != "* Add feature" {
+		t.Fatalf("wrong texts: %q, %q", r.Title, r.Description)
+	}
+
+	if r.Vars["Task"] != "TASK-123" || r.Vars[TmpVarIssueTitle] != "" || r.Vars[TmpVarTitle] != r.Title {
+		t.Fatal("wrong vars", r.Vars)
+	}
+
+	if len(r.Templates) != 2 || r.Templates[0].Text != "feature by me" || r.Templates[1].Error == "" {
+		t.Fatal("wrong templates", r.Templates)
+	}
+
+	if len(r.Notifications) != 1 || ns.args[TmpVarDescription] != r.Description {
+		t.Fatal("notification is not rendered", r.Notifications)
+	}
+}
+
+func TestRender_SameAsCreate(t *testing.T) {
+	var req gitlab.CreateMRRequest
+	gls := &gitlabStub{
+		f: func(method string, arg interface{}) {
+			if method == "CreateMR" {
+				req = arg.(gitlab.CreateMRRequest)
+			}
+		},
+	}
+
+	c := Core{
+		git: &templatesGit{
+			gitStub: gitStub{
+				r:       testRemote,
+				b:       "bugfix/TASK-123/fix-crash",
+				commits: []Commit{{Subject: "Fix crash"}},
+			},
+			files: map[string]string{
+				".gitlab/merge_request_templates/Bug.md": "## Bug {{.Task}}",
+			},
+		},
+		gitLab: gls,
+		hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+	}
+
+	mp := CreateMRParams{
+		TargetBranch: "develop",
+		BranchRegexp: regexp.MustCompile("(?P<TaskType>.*)/(?P<Task>.*)/(?P<BranchDescription>.*)"),
+		Draft:        true,
+		Labels: LabelsParams{
+			Static: []string{"team"},
+			Map:    map[string]map[string]string{"TaskType": {"bugfix": "bug"}},
+			BranchRules: []LabelRule{{
+				Regexp: regexp.MustCompile("TASK-"),
+				Labels: []string{"task"},
+			}},
+			Add: []string{"urgent"},
+		},
+		ProjectTemplates: map[string]string{"*": "bug"},
+	}
+
+	r, err := c.Render(context.Background(), RenderParams{MR: mp})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = c.CreateMR(context.Background(), mp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Title != "Draft: Fix crash" || r.Title != req.Title {
+		t.Fatalf("exp title of created MR %q, got %q", req.Title, r.Title)
+	}
+
+	if r.Description != "## Bug TASK-123" || r.Description != req.Description {
+		t.Fatalf("exp description of created MR %q, got %q", req.Description, r.Description)
+	}
+
+	if strings.Join(r.Labels, ",") != req.Labels || len(r.Labels) != 4 {
+		t.Fatalf("exp labels of created MR %q, got %q", req.Labels, r.Labels)
+	}
+}
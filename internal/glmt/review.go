@@ -0,0 +1,205 @@
+package glmt
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+)
+
+const (
+	ReviewReasonReviewer  = "reviewer"
+	ReviewReasonAssignee  = "assignee"
+	ReviewReasonMentioned = "mentioned"
+)
+
+type ReviewParams struct {
+	// Project limits MRs to one project (path with namespace).
+	Project string
+	// Labels MR should have all of.
+	Labels []string
+	// OlderThan skips MRs created less than OlderThan ago.
+	OlderThan time.Duration
+	// NewerThan skips MRs created more than NewerThan ago.
+	NewerThan time.Duration
+}
+
+// ReviewMR is merge request waiting for current user's review.
+type ReviewMR struct {
+	ID                int64     `json:"id"`
+	IID               int64     `json:"iid"`
+	ProjectID         int64     `json:"project_id"`
+	Title             string    `json:"title"`
+	Author            string    `json:"author"`
+	URL               string    `json:"url"`
+	CreatedAt         time.Time `json:"created_at"`
+	PipelineStatus    string    `json:"pipeline_status"`
+	ApprovalsGiven    int       `json:"approvals_given"`
+	ApprovalsRequired int       `json:"approvals_required"`
+	// Reasons tells why MR is in list: reviewer, assignee or mentioned.
+	Reasons []string `json:"reasons"`
+}
+
+// ReviewMRs lists opened MRs where current user is reviewer, assignee or
+// was mentioned. Current user's own MRs are skipped, as their author is
+// assigned to them. Oldest MRs go first.
+func (c *Core) ReviewMRs(ctx context.Context, params ReviewParams) ([]ReviewMR, error) {
+	cu, err := c.gitLab.CurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := gitlab.ListMRsRequest{
+		Project: params.Project,
+		State:   "opened",
+		Scope:   "all",
+		Labels:  params.Labels,
+	}
+
+	now := time.Now()
+	if params.OlderThan > 0 {
+		req.CreatedBefore = now.Add(-params.OlderThan)
+	}
+	if params.NewerThan > 0 {
+		req.CreatedAfter = now.Add(-params.NewerThan)
+	}
+
+	var (
+		found = map[int64]*ReviewMR{}
+		mrs   []gitlab.MergeRequest
+	)
+
+	add := func(mrs []gitlab.MergeRequest, reason string) {
+		for _, mr := range mrs {
+			if mr.Author.ID == cu.ID || !matchesReview(mr, req) {
+				continue
+			}
+
+			rmr, ok := found[mr.ID]
+			if !ok {
+				rmr = &ReviewMR{
+					ID:        mr.ID,
+					IID:       mr.IID,
+					ProjectID: mr.ProjectID,
+					Title:     mr.Title,
+					Author:    mr.Author.Username,
+					URL:       mr.URL,
+					CreatedAt: mr.CreatedAt,
+				}
+				found[mr.ID] = rmr
+			}
+
+			rmr.Reasons = append(rmr.Reasons, reason)
+		}
+	}
+
+	req.ReviewerID = cu.ID
+	mrs, err = c.gitLab.ListMRs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	add(mrs, ReviewReasonReviewer)
+
+	req.ReviewerID = 0
+	req.AssigneeID = cu.ID
+	mrs, err = c.gitLab.ListMRs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	add(mrs, ReviewReasonAssignee)
+
+	mrs, err = c.gitLab.MentionedMRs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	add(mrs, ReviewReasonMentioned)
+
+	result := make([]ReviewMR, 0, len(found))
+	for _, rmr := range found {
+		err = c.fillReviewDetails(ctx, rmr)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, *rmr)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+// fillReviewDetails requests pipeline and approvals, they are
+// not available in MR's list.
+func (c *Core) fillReviewDetails(ctx context.Context, rmr *ReviewMR) error {
+	p := strconv.FormatInt(rmr.ProjectID, 10)
+
+	mr, err := c.gitLab.GetMR(ctx, p, rmr.IID)
+	if err != nil {
+		return err
+	}
+
+	if mr.HeadPipeline != nil {
+		rmr.PipelineStatus = mr.HeadPipeline.Status
+	}
+
+	a, err := c.gitLab.MRApprovals(ctx, p, rmr.IID)
+	if err != nil {
+		return err
+	}
+
+	rmr.ApprovalsGiven = len(a.ApprovedBy)
+	rmr.ApprovalsRequired = a.ApprovalsRequired
+
+	return nil
+}
+
+// matchesReview checks MR against list filter. Todos can not be filtered
+// by GitLab so mentioned MRs are checked here.
+func matchesReview(mr gitlab.MergeRequest, req gitlab.ListMRsRequest) bool {
+	if mr.State != "" && mr.State != req.State {
+		return false
+	}
+
+	if !req.CreatedBefore.IsZero() && mr.CreatedAt.After(req.CreatedBefore) {
+		return false
+	}
+
+	if !req.CreatedAfter.IsZero() && mr.CreatedAt.Before(req.CreatedAfter) {
+		return false
+	}
+
+	if req.Project != "" {
+		ref := mr.References.Full
+		if i := strings.LastIndex(ref, "!"); i >= 0 {
+			ref = ref[:i]
+		}
+
+		if !strings.EqualFold(ref, req.Project) {
+			return false
+		}
+	}
+
+	for _, l := range req.Labels {
+		if !containsFold(mr.Labels, l) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsFold(ss []string, s string) bool {
+	for _, v := range ss {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,98 @@
+package glmt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+)
+
+func TestMatchesReview(t *testing.T) {
+	now := time.Now()
+	req := gitlab.ListMRsRequest{
+		Project:       "hummerd/glmt",
+		State:         "opened",
+		Labels:        []string{"backend"},
+		CreatedBefore: now.Add(-time.Hour),
+	}
+
+	mr := gitlab.MergeRequest{
+		State:      "opened",
+		Labels:     []string{"Backend", "bug"},
+		CreatedAt:  now.Add(-2 * time.Hour),
+		References: gitlab.References{Full: "hummerd/glmt!12"},
+	}
+
+	if !matchesReview(mr, req) {
+		t.Fatal("MR should match")
+	}
+
+	young := mr
+	young.CreatedAt = now
+	if matchesReview(young, req) {
+		t.Fatal("young MR should not match")
+	}
+
+	other := mr
+	other.References.Full = "hummerd/other!12"
+	if matchesReview(other, req) {
+		t.Fatal("MR from other project should not match")
+	}
+
+	unlabeled := mr
+	unlabeled.Labels = nil
+	if matchesReview(unlabeled, req) {
+		t.Fatal("MR without label should not match")
+	}
+
+	merged := mr
+	merged.State = "merged"
+	if matchesReview(merged, req) {
+		t.Fatal("merged MR should not match")
+	}
+}
+
+// reviewGitLab has MRs current user is mentioned in.
+type reviewGitLab struct {
+	mrStateGitLab
+	mentioned []gitlab.MergeRequest
+}
+
+func (rg *reviewGitLab) MentionedMRs(ctx context.Context) ([]gitlab.MergeRequest, error) {
+	rg.f("MentionedMRs", nil)
+	return rg.mentioned, nil
+}
+
+func TestReviewMRs_SkipOwn(t *testing.T) {
+	gls := &reviewGitLab{
+		mrStateGitLab: mrStateGitLab{
+			gitlabStub: gitlabStub{
+				f: func(string, interface{}) {},
+				listMRs: func(req gitlab.ListMRsRequest) []gitlab.MergeRequest {
+					if req.AssigneeID == 0 {
+						return nil
+					}
+
+					// stub's current user has ID 123
+					return []gitlab.MergeRequest{
+						{ID: 1, IID: 1, Author: gitlab.UserResponse{ID: 123, Username: "me"}},
+						{ID: 2, IID: 2, Author: gitlab.UserResponse{ID: 7, Username: "jane"}},
+					}
+				},
+			},
+		},
+		mentioned: []gitlab.MergeRequest{{ID: 3, IID: 3, Author: gitlab.UserResponse{ID: 123, Username: "me"}}},
+	}
+
+	c := Core{gitLab: gls}
+
+	rmrs, err := c.ReviewMRs(context.Background(), ReviewParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rmrs) != 1 || rmrs[0].Author != "jane" {
+		t.Fatal("own MRs should be skipped, got", rmrs)
+	}
+}
@@ -0,0 +1,269 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+)
+
+// Stack section of MR description is kept between markers, so it can be
+// replaced without touching the rest of description.
+const (
+	stackStartMarker = "<!-- glmt:stack -->"
+	stackEndMarker   = "<!-- glmt:stack:end -->"
+)
+
+// MRStatusRetargeted is a status of MR moved to another target branch by RetargetStack.
+const MRStatusRetargeted = "retargeted"
+
+// StackMR is MR of one branch of stack.
+type StackMR struct {
+	Branch       string `json:"branch"`
+	TargetBranch string `json:"target_branch"`
+	IID          int64  `json:"iid"`
+	URL          string `json:"url"`
+	// Status is one of MRStatus* constants.
+	Status string `json:"status"`
+}
+
+type RetargetParams struct {
+	// TargetBranch is a base branch of stack.
+	TargetBranch string
+}
+
+// CreateStack creates or updates MR for every branch of current branch's stack,
+// every MR targets parent branch and the first one targets params.TargetBranch.
+// Descriptions of MRs get section with links to all MRs of stack.
+func (c *Core) CreateStack(ctx context.Context, params CreateMRParams) ([]StackMR, error) {
+	if params.TargetBranch == "" {
+		return nil, errors.New("target branch is required")
+	}
+
+	ri, err := c.repoInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := c.git.BranchStack(ri.branch, params.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		smrs   = make([]StackMR, 0, len(branches))
+		target = params.TargetBranch
+		// nestedErr is an error happened after MR creation, it does not stop stack
+		nestedErr error
+	)
+
+	for _, b := range branches {
+		bp := params
+		bp.SourceBranch = b
+		bp.TargetBranch = target
+
+		mr, err := c.CreateMR(ctx, bp)
+		// MR without URL is not created
+		if err != nil && mr.URL == "" {
+			return smrs, fmt.Errorf("can not create MR for %s: %w", b, err)
+		}
+		if err != nil && nestedErr == nil {
+			nestedErr = err
+		}
+
+		smrs = append(smrs, StackMR{
+			Branch:       b,
+			TargetBranch: target,
+			IID:          mr.IID,
+			URL:          mr.URL,
+			Status:       mr.Status,
+		})
+
+		target = b
+	}
+
+	err = c.updateStackSections(ctx, ri.project, smrs)
+	if err != nil {
+		return smrs, err
+	}
+
+	return smrs, nestedErr
+}
+
+// RetargetStack moves MRs of current branch's stack targeting merged branches
+// to targets of merged MRs. Only retargeted MRs are returned.
+func (c *Core) RetargetStack(ctx context.Context, params RetargetParams) ([]StackMR, error) {
+	if params.TargetBranch == "" {
+		return nil, errors.New("target branch is required")
+	}
+
+	ri, err := c.repoInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	branches, err := c.git.BranchStack(ri.branch, params.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	// branches of stack and branches stack's MRs target (parents merged
+	// and gone from local stack), base of stack does not belong to it
+	inStack := make(map[string]bool, len(branches))
+	stackMRs := make([][]gitlab.MergeRequest, len(branches))
+	for i, b := range branches {
+		bri := ri
+		bri.branch = b
+
+		mrs, err := c.branchMRs(ctx, bri, "")
+		if err != nil {
+			return nil, err
+		}
+
+		inStack[b] = true
+		for _, mr := range mrs {
+			inStack[mr.TargetBranch] = true
+		}
+
+		stackMRs[i] = mrs
+	}
+
+	delete(inStack, params.TargetBranch)
+
+	var smrs []StackMR
+	for i, b := range branches {
+		for _, mr := range stackMRs[i] {
+			t, err := c.unmergedTarget(ctx, ri.project, mr.TargetBranch, inStack)
+			if err != nil {
+				return smrs, err
+			}
+
+			if t == mr.TargetBranch {
+				continue
+			}
+
+			umr, err := c.gitLab.UpdateMR(ctx, gitlab.UpdateMRRequest{
+				Project:      ri.project,
+				IID:          mr.IID,
+				TargetBranch: t,
+			})
+			if err != nil {
+				return smrs, err
+			}
+
+			smrs = append(smrs, StackMR{
+				Branch:       b,
+				TargetBranch: t,
+				IID:          mr.IID,
+				URL:          umr.URL,
+				Status:       MRStatusRetargeted,
+			})
+		}
+	}
+
+	return smrs, nil
+}
+
+// unmergedTarget follows merged MRs from target branch to the first branch
+// which is not merged. Only branches of stack are followed, so MR is never
+// moved from base of stack, even if base was merged somewhere once.
+func (c *Core) unmergedTarget(ctx context.Context, project, target string, inStack map[string]bool) (string, error) {
+	seen := map[string]bool{}
+
+	for inStack[target] && !seen[target] {
+		seen[target] = true
+
+		mrs, err := c.gitLab.ListMRs(ctx, gitlab.ListMRsRequest{
+			Project:      project,
+			State:        "merged",
+			SourceBranch: target,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		if len(mrs) == 0 {
+			break
+		}
+
+		log.Ctx(ctx).Debug().
+			Str("branch", target).
+			Str("target", mrs[0].TargetBranch).
+			Msg("target branch is merged")
+
+		target = mrs[0].TargetBranch
+	}
+
+	return target, nil
+}
+
+// updateStackSections replaces stack section in descriptions of stack's MRs.
+func (c *Core) updateStackSections(ctx context.Context, project string, smrs []StackMR) error {
+	if len(smrs) < 2 {
+		return nil
+	}
+
+	for i, smr := range smrs {
+		mr, err := c.gitLab.GetMR(ctx, project, smr.IID)
+		if err != nil {
+			return err
+		}
+
+		d := replaceStackSection(mr.Description, stackSection(smrs, i))
+		if d == mr.Description {
+			continue
+		}
+
+		_, err = c.gitLab.UpdateMR(ctx, gitlab.UpdateMRRequest{
+			Project:     project,
+			IID:         smr.IID,
+			Description: d,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stackSection lists MRs of stack, current MR is highlighted.
+func stackSection(smrs []StackMR, current int) string {
+	var sb strings.Builder
+
+	sb.WriteString(stackStartMarker + "\n")
+	sb.WriteString("**Stack:**\n")
+
+	for i, smr := range smrs {
+		item := fmt.Sprintf("!%d `%s`", smr.IID, smr.Branch)
+		if i == current {
+			item = "**" + item + "** (this MR)"
+		}
+
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, item))
+	}
+
+	sb.WriteString(stackEndMarker)
+
+	return sb.String()
+}
+
+// replaceStackSection replaces stack section of description or appends it.
+func replaceStackSection(d, section string) string {
+	start := strings.Index(d, stackStartMarker)
+	end := strings.Index(d, stackEndMarker)
+
+	if start < 0 || end < start {
+		if d == "" {
+			return section
+		}
+
+		return d + "\n\n" + section
+	}
+
+	return d[:start] + section + d[end+len(stackEndMarker):]
+}
@@ -0,0 +1,138 @@
+package glmt
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	hooksi "gitlab.com/gitlab-merge-tool/glmt/internal/hooks/impl"
+)
+
+// stackGit has branches stacked on each other.
+type stackGit struct {
+	gitStub
+	stack []string
+}
+
+func (sg *stackGit) BranchStack(branch, target string) ([]string, error) {
+	return sg.stack, nil
+}
+
+func TestCreateStack(t *testing.T) {
+	var (
+		created     []string
+		descUpdates int
+	)
+
+	gls := &gitlabStub{
+		f: func(method string, arg interface{}) {
+			switch method {
+			case "CreateMR":
+				req := arg.(gitlab.CreateMRRequest)
+				created = append(created, req.SourceBranch+"->"+req.TargetBranch)
+			case "UpdateMR":
+				req := arg.(gitlab.UpdateMRRequest)
+				if !strings.Contains(req.Description, stackStartMarker) {
+					t.Errorf("stack section is missing: %q", req.Description)
+				}
+				descUpdates++
+			}
+		},
+	}
+
+	c := Core{
+		git: &stackGit{
+			gitStub: gitStub{r: testRemote, b: "c"},
+			stack:   []string{"a", "b", "c"},
+		},
+		gitLab: gls,
+		hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+	}
+
+	smrs, err := c.CreateStack(context.Background(), CreateMRParams{TargetBranch: "develop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{"a->develop", "b->a", "c->b"}
+	if !reflect.DeepEqual(created, exp) {
+		t.Fatalf("exp MRs: %v, got: %v", exp, created)
+	}
+
+	if len(smrs) != 3 || smrs[2].TargetBranch != "b" || smrs[2].Status != MRStatusCreated {
+		t.Fatal("wrong stack MRs", smrs)
+	}
+
+	if descUpdates != 3 {
+		t.Fatal("every MR should get stack section, updated:", descUpdates)
+	}
+}
+
+func TestRetargetStack(t *testing.T) {
+	var updates []gitlab.UpdateMRRequest
+
+	gls := &gitlabStub{
+		f: func(method string, arg interface{}) {
+			if method == "UpdateMR" {
+				updates = append(updates, arg.(gitlab.UpdateMRRequest))
+			}
+		},
+		listMRs: func(req gitlab.ListMRsRequest) []gitlab.MergeRequest {
+			switch {
+			case req.State == "opened" && req.SourceBranch == "b":
+				return []gitlab.MergeRequest{{IID: 2, SourceBranch: "b", TargetBranch: "a"}}
+			case req.State == "opened" && req.SourceBranch == "c":
+				return []gitlab.MergeRequest{{IID: 3, SourceBranch: "c", TargetBranch: "b"}}
+			case req.State == "opened" && req.SourceBranch == "d":
+				return []gitlab.MergeRequest{{IID: 4, SourceBranch: "d", TargetBranch: "develop"}}
+			case req.State == "merged" && req.SourceBranch == "a":
+				return []gitlab.MergeRequest{{IID: 1, SourceBranch: "a", TargetBranch: "develop"}}
+			case req.State == "merged" && req.SourceBranch == "develop":
+				// base of stack was merged once, its MRs stay
+				return []gitlab.MergeRequest{{IID: 5, SourceBranch: "develop", TargetBranch: "master"}}
+			}
+
+			return nil
+		},
+	}
+
+	c := Core{
+		git: &stackGit{
+			gitStub: gitStub{r: testRemote, b: "c"},
+			stack:   []string{"d", "b", "c"},
+		},
+		gitLab: gls,
+	}
+
+	smrs, err := c.RetargetStack(context.Background(), RetargetParams{TargetBranch: "develop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(updates) != 1 || updates[0].IID != 2 || updates[0].TargetBranch != "develop" {
+		t.Fatal("only MR of b should be moved to develop", updates)
+	}
+
+	if len(smrs) != 1 || smrs[0].Status != MRStatusRetargeted {
+		t.Fatal("wrong retargeted MRs", smrs)
+	}
+}
+
+func TestReplaceStackSection(t *testing.T) {
+	smrs := []StackMR{{Branch: "a", IID: 1}, {Branch: "b", IID: 2}}
+
+	d := replaceStackSection("Description", stackSection(smrs, 1))
+	exp := "Description\n\n" + stackStartMarker + "\n**Stack:**\n1. !1 `a`\n2. **!2 `b`** (this MR)\n" + stackEndMarker
+	if d != exp {
+		t.Fatalf("exp: %q, got: %q", exp, d)
+	}
+
+	smrs = append(smrs, StackMR{Branch: "c", IID: 3})
+	d = replaceStackSection(d+"\nfooter", stackSection(smrs, 0))
+	if strings.Count(d, stackStartMarker) != 1 || !strings.Contains(d, "!3 `c`") || !strings.HasSuffix(d, "\nfooter") {
+		t.Fatalf("section is not replaced: %q", d)
+	}
+}
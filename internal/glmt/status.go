@@ -0,0 +1,169 @@
+package glmt
+
+import (
+	"context"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+)
+
+type StatusParams struct {
+	// TargetBranch is used to choose MR if there are several MRs for current branch.
+	TargetBranch string
+}
+
+// BranchStatus is a summary of current branch's MR.
+type BranchStatus struct {
+	IID          int64  `json:"iid"`
+	Title        string `json:"title"`
+	URL          string `json:"url"`
+	State        string `json:"state"`
+	Draft        bool   `json:"draft"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	// Pipeline is nil if MR has no pipeline.
+	Pipeline              *PipelineStatus `json:"pipeline"`
+	ApprovalsGiven        int             `json:"approvals_given"`
+	ApprovalsRequired     int             `json:"approvals_required"`
+	UnresolvedDiscussions int             `json:"unresolved_discussions"`
+	HasConflicts          bool            `json:"has_conflicts"`
+	// BehindTarget is a number of target branch's commits missing in MR.
+	BehindTarget int `json:"behind_target"`
+}
+
+type PipelineStatus struct {
+	ID         int64       `json:"id"`
+	Status     string      `json:"status"`
+	URL        string      `json:"url"`
+	FailedJobs []JobStatus `json:"failed_jobs"`
+}
+
+type JobStatus struct {
+	Name  string `json:"name"`
+	Stage string `json:"stage"`
+	URL   string `json:"url"`
+}
+
+// PipelineFinished is true if pipeline is absent or will not change its status
+// without user's actions.
+func (bs BranchStatus) PipelineFinished() bool {
+	if bs.Pipeline == nil {
+		return true
+	}
+
+	switch bs.Pipeline.Status {
+	case "success", "failed", "canceled", "skipped", "manual":
+		return true
+	}
+
+	return false
+}
+
+func (bs BranchStatus) PipelineFailed() bool {
+	if bs.Pipeline == nil {
+		return false
+	}
+
+	switch bs.Pipeline.Status {
+	case "failed", "canceled":
+		return true
+	}
+
+	return false
+}
+
+// Status returns summary of current branch's MR: pipeline, approvals,
+// discussions and conflicts.
+func (c *Core) Status(ctx context.Context, params StatusParams) (BranchStatus, error) {
+	var bs BranchStatus
+
+	ri, err := c.repoInfo()
+	if err != nil {
+		return bs, err
+	}
+
+	mr, err := c.branchMR(ctx, ri, params.TargetBranch)
+	if err != nil {
+		return bs, err
+	}
+
+	bs.IID = mr.IID
+	bs.Title = mr.Title
+	bs.URL = mr.URL
+	bs.State = mr.State
+	bs.Draft = isDraftMR(mr)
+	bs.SourceBranch = mr.SourceBranch
+	bs.TargetBranch = mr.TargetBranch
+	bs.HasConflicts = mr.HasConflicts
+	bs.BehindTarget = mr.DivergedCommitsCount
+
+	if mr.HeadPipeline != nil {
+		bs.Pipeline, err = c.pipelineStatus(ctx, ri.project, mr.HeadPipeline)
+		if err != nil {
+			return bs, err
+		}
+	}
+
+	a, err := c.gitLab.MRApprovals(ctx, ri.project, mr.IID)
+	if err != nil {
+		return bs, err
+	}
+
+	bs.ApprovalsGiven = len(a.ApprovedBy)
+	bs.ApprovalsRequired = a.ApprovalsRequired
+
+	ds, err := c.gitLab.MRDiscussions(ctx, ri.project, mr.IID)
+	if err != nil {
+		return bs, err
+	}
+
+	bs.UnresolvedDiscussions = unresolvedDiscussions(ds)
+
+	return bs, nil
+}
+
+func (c *Core) pipelineStatus(ctx context.Context, project string, p *gitlab.Pipeline) (*PipelineStatus, error) {
+	ps := &PipelineStatus{
+		ID:     p.ID,
+		Status: p.Status,
+		URL:    p.URL,
+	}
+
+	if p.Status != "failed" {
+		return ps, nil
+	}
+
+	jobs, err := c.gitLab.PipelineJobs(ctx, project, p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, j := range jobs {
+		if j.Status != "failed" || j.AllowFailure {
+			continue
+		}
+
+		ps.FailedJobs = append(ps.FailedJobs, JobStatus{
+			Name:  j.Name,
+			Stage: j.Stage,
+			URL:   j.URL,
+		})
+	}
+
+	return ps, nil
+}
+
+// unresolvedDiscussions counts discussions with resolvable notes
+// that are not resolved.
+func unresolvedDiscussions(ds []gitlab.Discussion) int {
+	count := 0
+	for _, d := range ds {
+		for _, n := range d.Notes {
+			if n.Resolvable && !n.Resolved {
+				count++
+				break
+			}
+		}
+	}
+
+	return count
+}
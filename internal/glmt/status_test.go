@@ -0,0 +1,104 @@
+package glmt
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+)
+
+// mrStateGitLab has approvals, discussions and pipeline jobs of MR.
+type mrStateGitLab struct {
+	gitlabStub
+	approvals   gitlab.Approvals
+	discussions []gitlab.Discussion
+	jobs        []gitlab.Job
+}
+
+func (mg *mrStateGitLab) MRApprovals(ctx context.Context, project string, iid int64) (gitlab.Approvals, error) {
+	mg.f("MRApprovals", iid)
+	return mg.approvals, nil
+}
+
+func (mg *mrStateGitLab) MRDiscussions(ctx context.Context, project string, iid int64) ([]gitlab.Discussion, error) {
+	mg.f("MRDiscussions", iid)
+	return mg.discussions, nil
+}
+
+func (mg *mrStateGitLab) PipelineJobs(ctx context.Context, project string, pipelineID int64) ([]gitlab.Job, error) {
+	mg.f("PipelineJobs", pipelineID)
+	return mg.jobs, nil
+}
+
+func TestStatus(t *testing.T) {
+	gs := &gitStub{
+		r: testRemote,
+		b: "feature/TASK-123/add-some-feature",
+	}
+
+	gls := &mrStateGitLab{
+		gitlabStub: gitlabStub{
+			f:   func(string, interface{}) {},
+			mrs: []gitlab.MergeRequest{{IID: 7}},
+			mr: gitlab.MergeRequest{
+				IID:                  7,
+				Title:                "Draft: TASK-123 Add some feature",
+				State:                "opened",
+				TargetBranch:         "develop",
+				DivergedCommitsCount: 3,
+				HeadPipeline: &gitlab.Pipeline{
+					ID:     1,
+					Status: "failed",
+				},
+			},
+		},
+		approvals: gitlab.Approvals{
+			ApprovalsRequired: 2,
+		},
+		discussions: []gitlab.Discussion{
+			{Notes: []gitlab.Note{{Resolvable: true, Resolved: true}}},
+			{Notes: []gitlab.Note{{Resolvable: true}, {Resolvable: true}}},
+			{Notes: []gitlab.Note{{System: true}}},
+		},
+		jobs: []gitlab.Job{
+			{Name: "unit", Stage: "test", Status: "failed"},
+			{Name: "lint", Stage: "test", Status: "failed", AllowFailure: true},
+			{Name: "build", Stage: "build", Status: "success"},
+		},
+	}
+
+	c := Core{
+		git:    gs,
+		gitLab: gls,
+	}
+
+	st, err := c.Status(context.Background(), StatusParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := BranchStatus{
+		IID:          7,
+		Title:        gls.mr.Title,
+		State:        "opened",
+		Draft:        true,
+		TargetBranch: "develop",
+		Pipeline: &PipelineStatus{
+			ID:         1,
+			Status:     "failed",
+			FailedJobs: []JobStatus{{Name: "unit", Stage: "test"}},
+		},
+		ApprovalsRequired:     2,
+		UnresolvedDiscussions: 1,
+		BehindTarget:          3,
+	}
+
+	if !reflect.DeepEqual(exp, st) {
+		t.Fatalf("expected status: %+v, got %+v", exp, st)
+	}
+
+	if !st.PipelineFinished() || !st.PipelineFailed() {
+		t.Fatal("pipeline should be finished and failed")
+	}
+}
@@ -0,0 +1,263 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TargetAuto chooses target branch with the closest merge base.
+const TargetAuto = "auto"
+
+// TargetRule maps current branch to target branch. Rule matches if branch
+// matches Branch glob and value of branch_regexp group Var matches Value glob,
+// empty fields are not checked.
+type TargetRule struct {
+	Branch string
+	Var    string
+	Value  string
+	// Target is a branch name, TargetAuto or glob choosing the newest of
+	// matching remote branches.
+	Target string
+}
+
+type TargetParams struct {
+	// Rules are checked in order, the first matching rule is used.
+	Rules []TargetRule
+	// Default is used if no rule matches, it can be TargetAuto or glob.
+	Default      string
+	BranchRegexp *regexp.Regexp
+	// Candidates are globs of remote branches TargetAuto chooses from,
+	// all remote branches are used if empty.
+	Candidates []string
+}
+
+// Target is a chosen target branch.
+type Target struct {
+	Branch string `json:"branch"`
+	// Reason tells why branch is chosen.
+	Reason string `json:"reason"`
+}
+
+// ResolveTarget chooses target branch for current branch by rules.
+func (c *Core) ResolveTarget(ctx context.Context, params TargetParams) (Target, error) {
+	var t Target
+
+	br, err := c.git.CurrentBranch()
+	if err != nil {
+		return t, err
+	}
+
+	vars := branchVars(params.BranchRegexp, br)
+
+	target, reason := params.Default, "default"
+	for _, r := range params.Rules {
+		if matchesTargetRule(r, br, vars) {
+			target, reason = r.Target, targetRuleReason(r)
+			break
+		}
+	}
+
+	if target == "" {
+		return t, errors.New("target branch is required")
+	}
+
+	if target == TargetAuto {
+		t, err = c.autoTarget(ctx, br, params.Candidates)
+		if err != nil {
+			return t, err
+		}
+
+		t.Reason = reason + ", " + t.Reason
+		return t, nil
+	}
+
+	if !isGlob(target) {
+		return Target{Branch: target, Reason: reason}, nil
+	}
+
+	rbs, err := c.git.RemoteBranches()
+	if err != nil {
+		return t, err
+	}
+
+	for _, rb := range rbs {
+		if matchGlob(target, rb) && (t.Branch == "" || versionLess(t.Branch, rb)) {
+			t.Branch = rb
+		}
+	}
+
+	if t.Branch == "" {
+		return t, fmt.Errorf("no remote branch matches %s", target)
+	}
+
+	t.Reason = reason + ", newest of " + target
+
+	return t, nil
+}
+
+// autoTarget chooses candidate with the least number of branch's commits missing
+// in it, that is with the closest merge base. Candidate with less missing commits
+// of its own wins a tie. Candidates already containing branch, like branch's own
+// upstream or branches stacked on it, are skipped.
+func (c *Core) autoTarget(ctx context.Context, branch string, candidates []string) (Target, error) {
+	var (
+		t                 Target
+		bestAhead, behind int
+	)
+
+	rbs, err := c.git.RemoteBranches()
+	if err != nil {
+		return t, err
+	}
+
+	upstream, err := c.git.Upstream(branch)
+	if err != nil {
+		return t, err
+	}
+
+	for _, rb := range rbs {
+		if rb == branch || rb == upstream || !matchesAnyGlob(candidates, rb) {
+			continue
+		}
+
+		a, b, err := c.git.Divergence(branch, rb)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("branch", rb).Msg("can not compare with target candidate")
+			continue
+		}
+
+		if a == 0 {
+			continue
+		}
+
+		if t.Branch == "" || a < bestAhead || (a == bestAhead && b < behind) {
+			t.Branch, bestAhead, behind = rb, a, b
+		}
+	}
+
+	if t.Branch == "" {
+		return t, errors.New("no remote branch to choose target from")
+	}
+
+	t.Reason = fmt.Sprintf("closest merge base, %d commit(s) ahead", bestAhead)
+
+	return t, nil
+}
+
+func matchesTargetRule(r TargetRule, branch string, vars map[string]string) bool {
+	if r.Branch == "" && r.Var == "" {
+		return false
+	}
+
+	if r.Branch != "" && !matchGlob(r.Branch, branch) {
+		return false
+	}
+
+	if r.Var != "" {
+		v, ok := vars[r.Var]
+		if !ok || !matchGlob(r.Value, v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func targetRuleReason(r TargetRule) string {
+	var conds []string
+	if r.Branch != "" {
+		conds = append(conds, "branch "+r.Branch)
+	}
+	if r.Var != "" {
+		conds = append(conds, r.Var+"="+r.Value)
+	}
+
+	return "rule " + strings.Join(conds, ", ")
+}
+
+// branchVars returns values of branch regexp's named groups.
+func branchVars(re *regexp.Regexp, branch string) map[string]string {
+	vars := map[string]string{}
+	if re == nil {
+		return vars
+	}
+
+	m := re.FindStringSubmatch(branch)
+	if m == nil {
+		return vars
+	}
+
+	for i, n := range re.SubexpNames() {
+		if n != "" {
+			vars[n] = m[i]
+		}
+	}
+
+	return vars
+}
+
+func isGlob(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+func matchGlob(pattern, s string) bool {
+	ok, err := path.Match(pattern, s)
+	return ok && err == nil
+}
+
+func matchesAnyGlob(patterns []string, s string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, p := range patterns {
+		if matchGlob(p, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// versionLess compares strings with numbers compared by value,
+// so release/1.9 is less than release/1.10.
+func versionLess(a, b string) bool {
+	for a != "" && b != "" {
+		ap, ar := versionPart(a)
+		bp, br := versionPart(b)
+
+		if ap != bp {
+			an, aerr := strconv.Atoi(ap)
+			bn, berr := strconv.Atoi(bp)
+			if aerr == nil && berr == nil && an != bn {
+				return an < bn
+			}
+
+			return ap < bp
+		}
+
+		a, b = ar, br
+	}
+
+	return len(a) < len(b)
+}
+
+// versionPart splits s to leading run of digits or non-digits and the rest.
+func versionPart(s string) (string, string) {
+	digit := unicode.IsDigit(rune(s[0]))
+
+	i := 1
+	for i < len(s) && unicode.IsDigit(rune(s[i])) == digit {
+		i++
+	}
+
+	return s[:i], s[i:]
+}
@@ -0,0 +1,140 @@
+package glmt
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestResolveTarget(t *testing.T) {
+	remotes := []string{"develop", "master", "release/1.9", "release/1.10", "release/1.2"}
+
+	params := TargetParams{
+		Rules: []TargetRule{
+			{Branch: "hotfix/*", Target: "release/*"},
+			{Var: "Type", Value: "feat*", Target: "develop"},
+			{Branch: "experiment/*", Target: TargetAuto},
+		},
+		Default:      "master",
+		BranchRegexp: regexp.MustCompile(`(?P<Type>\w+)/(?P<Task>.+)`),
+	}
+
+	cases := []struct {
+		branch string
+		target string
+	}{
+		{"hotfix/crash", "release/1.10"},
+		{"feature/new", "develop"},
+		{"fix/bug", "master"},
+		{"experiment/x", "develop"},
+	}
+
+	for _, tc := range cases {
+		c := Core{
+			git: &targetGit{
+				gitStub:        gitStub{b: tc.branch},
+				remoteBranches: remotes,
+				ahead:          map[string]int{"develop": 1, "master": 3, "release/1.9": 5, "release/1.10": 4, "release/1.2": 7},
+			},
+		}
+
+		target, err := c.ResolveTarget(context.Background(), params)
+		if err != nil {
+			t.Fatal(tc.branch, err)
+		}
+
+		if target.Branch != tc.target || target.Reason == "" {
+			t.Errorf("exp target %s for %s, got %+v", tc.target, tc.branch, target)
+		}
+	}
+}
+
+// targetGit reports divergence of current branch from remote branches.
+type targetGit struct {
+	gitStub
+	remoteBranches []string
+	// ahead and behind are numbers of commits by remote branch.
+	ahead, behind map[string]int
+	upstream      string
+}
+
+func (tg *targetGit) RemoteBranches() ([]string, error) {
+	return tg.remoteBranches, nil
+}
+
+func (tg *targetGit) Divergence(branch, target string) (int, int, error) {
+	return tg.ahead[target], tg.behind[target], nil
+}
+
+func (tg *targetGit) Upstream(branch string) (string, error) {
+	return tg.upstream, nil
+}
+
+func TestResolveTarget_Auto(t *testing.T) {
+	c := Core{
+		git: &targetGit{
+			gitStub:        gitStub{b: "feature"},
+			remoteBranches: []string{"develop", "feature", "feature-child", "master", "mine/feature", "release/2"},
+			// feature-child is stacked on feature, mine/feature is feature's upstream
+			// with unpushed commits
+			ahead:    map[string]int{"develop": 2, "feature": 0, "feature-child": 0, "master": 2, "mine/feature": 1, "release/2": 2},
+			behind:   map[string]int{"develop": 10, "feature-child": 1, "master": 3, "release/2": 1},
+			upstream: "mine/feature",
+		},
+	}
+
+	target, err := c.ResolveTarget(context.Background(), TargetParams{Default: TargetAuto})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if target.Branch != "release/2" {
+		t.Fatal("exp release/2 as the closest candidate, got", target)
+	}
+
+	target, err = c.ResolveTarget(context.Background(), TargetParams{
+		Default:    TargetAuto,
+		Candidates: []string{"develop", "master"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if target.Branch != "master" {
+		t.Fatal("exp master as the closest candidate, got", target)
+	}
+}
+
+func TestResolveTarget_NoMatch(t *testing.T) {
+	c := Core{
+		git: &targetGit{
+			gitStub:        gitStub{b: "hotfix/crash"},
+			remoteBranches: []string{"master"},
+		},
+	}
+
+	_, err := c.ResolveTarget(context.Background(), TargetParams{Default: "release/*"})
+	if err == nil {
+		t.Fatal("exp error for glob matching no branch")
+	}
+}
+
+func TestVersionLess(t *testing.T) {
+	cases := []struct {
+		a, b string
+		less bool
+	}{
+		{"release/1.9", "release/1.10", true},
+		{"release/1.10", "release/1.9", false},
+		{"release/1.2", "release/1.2.1", true},
+		{"release/a", "release/b", true},
+		{"v2", "v10", true},
+		{"v2", "v2", false},
+	}
+
+	for _, tc := range cases {
+		if versionLess(tc.a, tc.b) != tc.less {
+			t.Errorf("versionLess(%s, %s) exp %v", tc.a, tc.b, tc.less)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+)
+
+var ErrNoTemplate = errors.New("merge request template not found")
+
+// GitLab keeps MR description templates as markdown files in mrTemplatesDir.
+const (
+	mrTemplatesDir = ".gitlab/merge_request_templates"
+	mrTemplateExt  = ".md"
+)
+
+// Sources of MR templates.
+const (
+	TemplateSourceLocal  = "local"
+	TemplateSourceGitLab = "gitlab"
+)
+
+// anyProject is a key of ProjectTemplates used for projects missing in map.
+const anyProject = "*"
+
+type TemplatesParams struct {
+	// TargetBranch is a ref templates are read at from GitLab,
+	// empty means project's default branch.
+	TargetBranch string
+	// ProjectTemplates maps project path to name of its default template,
+	// "*" is used for projects missing in map.
+	ProjectTemplates map[string]string
+}
+
+// MRTemplates are MR description templates of current project.
+type MRTemplates struct {
+	Names []string `json:"names"`
+	// Default is empty if project has no default template.
+	Default string `json:"default"`
+	// Source is one of TemplateSource* constants.
+	Source string `json:"source"`
+}
+
+// MRTemplates lists MR description templates of current project. Templates are
+// read from local checkout and, if there are none, from GitLab.
+func (c *Core) MRTemplates(ctx context.Context, params TemplatesParams) (MRTemplates, error) {
+	var mt MRTemplates
+
+	ri, err := c.repoInfo()
+	if err != nil {
+		return mt, err
+	}
+
+	ts, err := c.templateSource(ctx, ri, params.TargetBranch)
+	if err != nil {
+		return mt, err
+	}
+
+	mt.Source = ts.source
+	mt.Default = projectTemplate(params.ProjectTemplates, ri.project)
+	for n := range ts.files {
+		mt.Names = append(mt.Names, n)
+	}
+
+	sort.Strings(mt.Names)
+
+	return mt, nil
+}
+
+// descriptionTemplate returns template of MR description: named template, project's
+// default template or params.DescriptionTemplate if project has no templates.
+func (c *Core) descriptionTemplate(ctx context.Context, ri repoInfo, params CreateMRParams) (string, error) {
+	name := params.Template
+	if name == "" {
+		name = projectTemplate(params.ProjectTemplates, ri.project)
+	}
+
+	if name == "" {
+		return params.DescriptionTemplate, nil
+	}
+
+	ts, err := c.templateSource(ctx, ri, params.TargetBranch)
+	if err != nil {
+		return "", err
+	}
+
+	t, err := c.readTemplate(ctx, ts, name)
+	if errors.Is(err, ErrNoTemplate) && params.Template == "" {
+		// default template may be configured for projects without it
+		log.Ctx(ctx).Warn().
+			Str("template", name).
+			Msg("default MR template not found, using description from config")
+
+		return params.DescriptionTemplate, nil
+	}
+
+	return t, err
+}
+
+// projectTemplate returns default template of project, project paths are case
+// insensitive.
+func projectTemplate(pts map[string]string, project string) string {
+	if t, ok := lookupFold(pts, project); ok {
+		return t
+	}
+
+	return pts[anyProject]
+}
+
+// lookupFold returns value of case insensitive key. Exact match is preferred,
+// then keys are checked in sorted order, so result does not depend on map order.
+func lookupFold(m map[string]string, key string) (string, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+
+	sort.Strings(ks)
+
+	for _, k := range ks {
+		if strings.EqualFold(k, key) {
+			return m[k], true
+		}
+	}
+
+	return "", false
+}
+
+// templateSource tells where templates are read from: local checkout or
+// target project in GitLab.
+type templateSource struct {
+	source  string
+	project string
+	ref     string
+	// files maps template names to their paths.
+	files map[string]string
+}
+
+func (c *Core) templateSource(ctx context.Context, ri repoInfo, ref string) (templateSource, error) {
+	ts := templateSource{
+		source:  TemplateSourceLocal,
+		project: ri.project,
+		ref:     ref,
+		files:   map[string]string{},
+	}
+
+	names, err := c.git.ReadDir(mrTemplatesDir)
+	if err != nil {
+		return ts, fmt.Errorf("can not list local MR templates: %w", err)
+	}
+
+	for _, n := range names {
+		addTemplateFile(ts.files, n)
+	}
+
+	if len(ts.files) == 0 {
+		ts.source = TemplateSourceGitLab
+
+		es, err := c.gitLab.RepositoryTree(ctx, ri.project, mrTemplatesDir, ref)
+		if ge, ok := gitlab.AsGitlabError(err); ok && ge.IsNotFound() {
+			err = nil
+		}
+		if err != nil {
+			return ts, fmt.Errorf("can not list MR templates: %w", err)
+		}
+
+		for _, e := range es {
+			if e.Type == "blob" {
+				addTemplateFile(ts.files, e.Name)
+			}
+		}
+	}
+
+	return ts, nil
+}
+
+func (c *Core) readTemplate(ctx context.Context, ts templateSource, name string) (string, error) {
+	f, ok := lookupFold(ts.files, name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNoTemplate, name)
+	}
+
+	var (
+		content []byte
+		err     error
+	)
+
+	if ts.source == TemplateSourceLocal {
+		content, err = c.git.ReadFile(f)
+	} else {
+		content, err = c.gitLab.RepositoryFile(ctx, ts.project, f, ts.ref)
+	}
+	if err != nil {
+		return "", fmt.Errorf("can not read MR template %s: %w", name, err)
+	}
+
+	return string(content), nil
+}
+
+func addTemplateFile(files map[string]string, file string) {
+	if strings.HasSuffix(file, mrTemplateExt) {
+		files[strings.TrimSuffix(file, mrTemplateExt)] = path.Join(mrTemplatesDir, file)
+	}
+}
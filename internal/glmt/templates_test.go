@@ -0,0 +1,234 @@
+package glmt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/gitlab"
+	hooksi "gitlab.com/gitlab-merge-tool/glmt/internal/hooks/impl"
+)
+
+// templatesGit has worktree files.
+type templatesGit struct {
+	gitStub
+	// files are contents by path.
+	files map[string]string
+}
+
+func (tg *templatesGit) ReadDir(dir string) ([]string, error) {
+	var names []string
+	for f := range tg.files {
+		if path.Dir(f) == dir {
+			names = append(names, path.Base(f))
+		}
+	}
+
+	return names, nil
+}
+
+func (tg *templatesGit) ReadFile(file string) ([]byte, error) {
+	content, ok := tg.files[file]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+
+	return []byte(content), nil
+}
+
+// templatesGitLab has repository files.
+type templatesGitLab struct {
+	gitlabStub
+	// files are contents by path.
+	files map[string]string
+}
+
+func (tg *templatesGitLab) RepositoryTree(ctx context.Context, project, dir, ref string) ([]gitlab.TreeEntry, error) {
+	tg.f("RepositoryTree", dir)
+
+	var es []gitlab.TreeEntry
+	for f := range tg.files {
+		if path.Dir(f) == dir {
+			es = append(es, gitlab.TreeEntry{Name: path.Base(f), Type: "blob", Path: f})
+		}
+	}
+
+	return es, nil
+}
+
+func (tg *templatesGitLab) RepositoryFile(ctx context.Context, project, file, ref string) ([]byte, error) {
+	tg.f("RepositoryFile", file)
+	return []byte(tg.files[file]), nil
+}
+
+func TestCreateMR_Template(t *testing.T) {
+	tests := []struct {
+		name     string
+		local    map[string]string
+		remote   map[string]string
+		params   CreateMRParams
+		exp      string
+		expError error
+	}{{
+		name: "local template",
+		local: map[string]string{
+			".gitlab/merge_request_templates/Bug.md":     "## Bug in {{.ProjectName}}",
+			".gitlab/merge_request_templates/Feature.md": "## Feature",
+		},
+		params: CreateMRParams{Template: "bug"},
+		exp:    "## Bug in hummerd/client_golang",
+	}, {
+		name: "gitlab template",
+		remote: map[string]string{
+			".gitlab/merge_request_templates/Default.md": "## Default {{.BranchName}}",
+		},
+		params: CreateMRParams{
+			ProjectTemplates: map[string]string{"Hummerd/Client_golang": "Default"},
+		},
+		exp: "## Default feature",
+	}, {
+		name:  "missing default template",
+		local: map[string]string{".gitlab/merge_request_templates/Bug.md": "## Bug"},
+		params: CreateMRParams{
+			DescriptionTemplate: "config description",
+			ProjectTemplates:    map[string]string{"*": "Default"},
+		},
+		exp: "config description",
+	}, {
+		name:     "missing template",
+		params:   CreateMRParams{Template: "Bug"},
+		expError: ErrNoTemplate,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req gitlab.CreateMRRequest
+			gls := &templatesGitLab{
+				gitlabStub: gitlabStub{
+					f: func(method string, arg interface{}) {
+						if method == "CreateMR" {
+							req = arg.(gitlab.CreateMRRequest)
+						}
+					},
+				},
+				files: tt.remote,
+			}
+
+			c := Core{
+				git: &templatesGit{
+					gitStub: gitStub{r: testRemote, b: "feature"},
+					files:   tt.local,
+				},
+				gitLab: gls,
+				hooks:  hooksi.NewHooks(config.Hooks{}, nil, nil),
+			}
+
+			tt.params.TargetBranch = "master"
+			_, err := c.CreateMR(context.Background(), tt.params)
+			if !errors.Is(err, tt.expError) {
+				t.Fatalf("expected error %v, got %v", tt.expError, err)
+			}
+
+			if req.Description != tt.exp {
+				t.Fatalf("exp description: %q, got: %q", tt.exp, req.Description)
+			}
+		})
+	}
+}
+
+func TestMRTemplates(t *testing.T) {
+	c := Core{
+		git: &gitStub{
+			r: testRemote,
+			b: "feature",
+		},
+		gitLab: &templatesGitLab{
+			gitlabStub: gitlabStub{f: func(string, interface{}) {}},
+			files: map[string]string{
+				".gitlab/merge_request_templates/Feature.md": "",
+				".gitlab/merge_request_templates/Bug.md":     "",
+				".gitlab/merge_request_templates/notes.txt":  "",
+			},
+		},
+	}
+
+	mt, err := c.MRTemplates(context.Background(), TemplatesParams{
+		ProjectTemplates: map[string]string{"*": "Feature"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := MRTemplates{
+		Names:   []string{"Bug", "Feature"},
+		Default: "Feature",
+		Source:  TemplateSourceGitLab,
+	}
+	if !reflect.DeepEqual(mt, exp) {
+		t.Fatalf("exp templates: %+v, got: %+v", exp, mt)
+	}
+}
+
+func TestProjectTemplate(t *testing.T) {
+	pts := map[string]string{
+		"Group/Project": "Upper",
+		"group/project": "Lower",
+		"group/other":   "Other",
+		"*":             "Any",
+	}
+
+	cases := map[string]string{
+		"Group/Project": "Upper",
+		"group/project": "Lower",
+		"GROUP/PROJECT": "Upper",
+		"Group/Other":   "Other",
+		"group/unknown": "Any",
+	}
+
+	for i := 0; i < 10; i++ {
+		for project, exp := range cases {
+			if got := projectTemplate(pts, project); got != exp {
+				t.Fatalf("exp template %s for %s, got %s", exp, project, got)
+			}
+		}
+	}
+}
+
+func TestReadTemplate_Case(t *testing.T) {
+	c := Core{
+		git: &templatesGit{
+			files: map[string]string{
+				".gitlab/merge_request_templates/Default.md": "Upper",
+				".gitlab/merge_request_templates/default.md": "Lower",
+			},
+		},
+	}
+
+	ts, err := c.templateSource(context.Background(), repoInfo{}, "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"Default": "Upper",
+		"default": "Lower",
+		"DEFAULT": "Upper",
+	}
+
+	for i := 0; i < 10; i++ {
+		for name, exp := range cases {
+			got, err := c.readTemplate(context.Background(), ts, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != exp {
+				t.Fatalf("exp template %s for %s, got %s", exp, name, got)
+			}
+		}
+	}
+}
@@ -1,15 +1,25 @@
 package glmt
 
 import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/rs/zerolog/log"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/hooks"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/team"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/templating"
 )
 
 const (
 	TmpVarProjectName          = "ProjectName"
 	TmpVarBranchName           = "BranchName"
 	TmpVarRemote               = "Remote"
+	TmpVarRemoteName           = "RemoteName"
 	TmpVarTargetBranchName     = "TargetBranchName"
 	TmpVarTitle                = "Title"
 	TmpVarDescription          = "Description"
@@ -18,23 +28,122 @@ const (
 	TmpVarNotificationMentions = "NotificationMentions"
 	TmpVarMRChangesCount       = "ChangesCount"
 	TmpVarUsername             = "Username"
+	// TmpVarCommits is a list of Commit from merge base with target branch.
+	TmpVarCommits      = "Commits"
+	TmpVarFilesChanged = "FilesChanged"
+	TmpVarInsertions   = "Insertions"
+	TmpVarDeletions    = "Deletions"
+	// TmpVarBackportVersion is the last part of backport target branch,
+	// like "1.4" for "release/1.4".
+	TmpVarBackportVersion = "BackportVersion"
+	TmpVarOriginalMRURL   = "OriginalMergeRequestURL"
 )
 
-func getTextArgs(branch, projectName, remote, username string, params CreateMRParams, members []*team.Member) map[string]string {
-	r := map[string]string{}
+// wellKnownArgs are zero values of well known variables, they are set for every
+// template, so templates can be shared by commands providing only some of them.
+var wellKnownArgs = map[string]interface{}{
+	TmpVarProjectName:          "",
+	TmpVarBranchName:           "",
+	TmpVarRemote:               "",
+	TmpVarRemoteName:           "",
+	TmpVarTargetBranchName:     "",
+	TmpVarTitle:                "",
+	TmpVarDescription:          "",
+	TmpVarMRURL:                "",
+	TmpVarGitlabMentions:       "",
+	TmpVarNotificationMentions: "",
+	TmpVarMRChangesCount:       "",
+	TmpVarUsername:             "",
+	TmpVarCommits:              []Commit{},
+	TmpVarFilesChanged:         0,
+	TmpVarInsertions:           0,
+	TmpVarDeletions:            0,
+	TmpVarBackportVersion:      "",
+	TmpVarOriginalMRURL:        "",
+	TmpVarIssueTitle:           "",
+	TmpVarIssueLabels:          "",
+	TmpVarIssueMilestone:       "",
+	TmpVarIssueURL:             "",
+	TmpVarJiraKey:              "",
+	TmpVarJiraSummary:          "",
+	TmpVarJiraType:             "",
+	TmpVarJiraStatus:           "",
+	TmpVarJiraURL:              "",
+}
 
-	gitlabMentions := make([]string, 0, len(members))
-	for _, m := range members {
-		gitlabMentions = append(gitlabMentions, "@"+m.Username)
+// TemplateVars returns sorted names of variables available in templates,
+// they are well known variables and groups of branch regexp.
+func TemplateVars(br *regexp.Regexp) []string {
+	vars := make([]string, 0, len(wellKnownArgs))
+	for k := range wellKnownArgs {
+		vars = append(vars, k)
 	}
 
+	if br != nil {
+		for _, n := range br.SubexpNames() {
+			if _, ok := wellKnownArgs[n]; n != "" && !ok {
+				vars = append(vars, n)
+			}
+		}
+	}
+
+	sort.Strings(vars)
+
+	return vars
+}
+
+// ValidateTemplates checks templates by name for syntax errors and unknown
+// variables, all invalid templates are reported in one error.
+func ValidateTemplates(br *regexp.Regexp, templates map[string]string) error {
+	vars := TemplateVars(br)
+
+	names := make([]string, 0, len(templates))
+	for n := range templates {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+
+	var errs []string
+	for _, n := range names {
+		err := templating.Validate(n, templates[n], vars)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid templates: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// templateArgs returns copy of text args with zero values of absent well known variables.
+func templateArgs(ta map[string]interface{}) map[string]interface{} {
+	r := make(map[string]interface{}, len(wellKnownArgs)+len(ta))
+	for k, v := range wellKnownArgs {
+		r[k] = v
+	}
+
+	for k, v := range ta {
+		r[k] = v
+	}
+
+	return r
+}
+
+func getTextArgs(ri repoInfo, username string, params CreateMRParams, members []*team.Member) map[string]interface{} {
+	r := map[string]interface{}{}
+
 	defer func() {
 		// in the end override values with well known
-		r[TmpVarProjectName] = projectName
-		r[TmpVarBranchName] = branch
+		r[TmpVarProjectName] = ri.project
+		r[TmpVarBranchName] = ri.branch
 		r[TmpVarTargetBranchName] = params.TargetBranch
-		r[TmpVarGitlabMentions] = strings.Join(gitlabMentions, ", ")
-		r[TmpVarRemote] = remote
+		r[TmpVarGitlabMentions] = gitlabMentions(members)
+		r[TmpVarRemote] = ri.remote
+		r[TmpVarRemoteName] = ri.remoteName
 		r[TmpVarUsername] = username
 	}()
 
@@ -47,7 +156,7 @@ func getTextArgs(branch, projectName, remote, username string, params CreateMRPa
 		return r
 	}
 
-	match := params.BranchRegexp.FindStringSubmatch(branch)
+	match := params.BranchRegexp.FindStringSubmatch(ri.branch)
 	for i := 1; i < len(subNames); i++ {
 		m := ""
 		if len(match) > i {
@@ -58,3 +167,63 @@ func getTextArgs(branch, projectName, remote, username string, params CreateMRPa
 
 	return r
 }
+
+// addCommitArgs adds commits and diff statistics of current branch to text args
+// and returns commits. Target branch may be absent in local repository, so errors
+// are only logged and variables are left empty.
+func (c *Core) addCommitArgs(ctx context.Context, ri repoInfo, target string, ta map[string]interface{}) []Commit {
+	ta[TmpVarCommits] = []Commit{}
+	ta[TmpVarFilesChanged] = 0
+	ta[TmpVarInsertions] = 0
+	ta[TmpVarDeletions] = 0
+
+	cs, err := c.git.BranchCommits(ri.branch, target)
+	if err != nil {
+		log.Ctx(ctx).Warn().
+			Err(err).
+			Msg("can not get commits of branch")
+
+		return nil
+	}
+
+	ta[TmpVarCommits] = cs
+
+	ds, err := c.git.DiffStat(ri.branch, target)
+	if err != nil {
+		log.Ctx(ctx).Warn().
+			Err(err).
+			Msg("can not get diff statistics of branch")
+
+		return cs
+	}
+
+	ta[TmpVarFilesChanged] = ds.FilesChanged
+	ta[TmpVarInsertions] = ds.Insertions
+	ta[TmpVarDeletions] = ds.Deletions
+
+	return cs
+}
+
+// hookParams converts text args to hooks params, lists like commits are skipped.
+func hookParams(ta map[string]interface{}) hooks.Params {
+	p := hooks.Params{}
+	for k, v := range ta {
+		switch v := v.(type) {
+		case string:
+			p[k] = v
+		case int:
+			p[k] = strconv.Itoa(v)
+		}
+	}
+
+	return p
+}
+
+func gitlabMentions(members []*team.Member) string {
+	mentions := make([]string, 0, len(members))
+	for _, m := range members {
+		mentions = append(mentions, "@"+m.Username)
+	}
+
+	return strings.Join(mentions, ", ")
+}
@@ -9,6 +9,8 @@ import (
 type Runner interface {
 	RunAfter(ctx context.Context, params Params) error
 	RunBefore(ctx context.Context, params Params) error
+	// RunMerge runs commands before MR is merged.
+	RunMerge(ctx context.Context, params Params) error
 }
 
 type Params map[string]string
@@ -16,6 +16,7 @@ import (
 type Hooks struct {
 	afterCommands  map[string][]string
 	beforeCommands map[string][]string
+	mergeCommands  map[string][]string
 
 	timeout time.Duration
 
@@ -34,6 +35,7 @@ func NewHooks(cfg config.Hooks, stdout io.Writer, stderr io.Writer) *Hooks {
 	return &Hooks{
 		afterCommands:  filterCommands(cfg.AfterCommands),
 		beforeCommands: filterCommands(cfg.BeforeCommands),
+		mergeCommands:  filterCommands(cfg.MergeCommands),
 
 		timeout: timeout,
 
@@ -50,6 +52,10 @@ func (h Hooks) RunBefore(ctx context.Context, params hooks.Params) error {
 	return h.run(ctx, h.beforeCommands, params)
 }
 
+func (h Hooks) RunMerge(ctx context.Context, params hooks.Params) error {
+	return h.run(ctx, h.mergeCommands, params)
+}
+
 func (h Hooks) run(
 	ctx context.Context,
 	commands map[string][]string,
@@ -100,3 +100,26 @@ func TestHooks_RunAfter_Deadline(t *testing.T) {
 		t.Fatal("No error")
 	}
 }
+
+func TestHooks_RunMerge(t *testing.T) {
+	const expBuf = "TASK-1\n"
+
+	var buf bytes.Buffer
+	h := impl.NewHooks(config.Hooks{
+		MergeCommands: map[string][]string{
+			"test": {
+				"sh", "-c", "echo $GLMT_TASK",
+			},
+		},
+	}, &buf, &buf)
+
+	err := h.RunMerge(context.Background(), hooks.Params{
+		"Task": "TASK-1",
+	})
+	switch {
+	case err != nil:
+		t.Fatal(err)
+	case buf.String() != expBuf:
+		t.Fatal("Invalid output", buf.String())
+	}
+}
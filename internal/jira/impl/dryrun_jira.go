@@ -0,0 +1,42 @@
+package impl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/jira"
+)
+
+// NewDryRunJira creates Jira that only prints requests.
+func NewDryRunJira(out io.StringWriter, cfg config.Jira) *DryRunJira {
+	return &DryRunJira{
+		out:  out,
+		host: strings.TrimRight(cfg.URL, "/"),
+	}
+}
+
+type DryRunJira struct {
+	out  io.StringWriter
+	host string
+}
+
+func (j *DryRunJira) Issue(ctx context.Context, key string) (jira.Issue, error) {
+	_, _ = j.out.WriteString(fmt.Sprintf("Sending get jira issue request:\n%s %s%s\n\n",
+		http.MethodGet, j.host, issuePath(key)))
+
+	return jira.Issue{
+		Key: key,
+		URL: issueURL(j.host, key),
+	}, nil
+}
+
+func (j *DryRunJira) Transition(ctx context.Context, key, status string) error {
+	_, _ = j.out.WriteString(fmt.Sprintf("Sending jira transition request:\n%s %s%s/transitions\nto status %q\n\n",
+		http.MethodPost, j.host, issuePath(key), status))
+
+	return nil
+}
@@ -0,0 +1,241 @@
+// Package impl implements http client for Jira
+package impl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/jira"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// NewHTTPJira creates Jira client. If username is set token is sent with basic
+// auth (Jira Cloud API token), otherwise as bearer token (Jira Server personal access token).
+func NewHTTPJira(cfg config.Jira) *HTTPJira {
+	timeout := time.Duration(cfg.Timeout)
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &HTTPJira{
+		c: &http.Client{
+			Timeout: timeout,
+		},
+		host:     strings.TrimRight(cfg.URL, "/"),
+		username: cfg.Username,
+		token:    cfg.Token,
+	}
+}
+
+type HTTPJira struct {
+	c        *http.Client
+	host     string
+	username string
+	token    string
+}
+
+type issueResponse struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary   string `json:"summary"`
+		IssueType struct {
+			Name string `json:"name"`
+		} `json:"issuetype"`
+		Status struct {
+			Name string `json:"name"`
+		} `json:"status"`
+	} `json:"fields"`
+}
+
+type transitionsResponse struct {
+	Transitions []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		To   struct {
+			Name string `json:"name"`
+		} `json:"to"`
+	} `json:"transitions"`
+}
+
+type transitionRequest struct {
+	Transition struct {
+		ID string `json:"id"`
+	} `json:"transition"`
+}
+
+func (j *HTTPJira) Issue(ctx context.Context, key string) (jira.Issue, error) {
+	var is jira.Issue
+
+	query := url.Values{}
+	query.Set("fields", "summary,issuetype,status")
+
+	var resp issueResponse
+	err := j.do(ctx, http.MethodGet, issuePath(key), query, nil, http.StatusOK, &resp)
+	if err != nil {
+		return is, fmt.Errorf("can not get jira issue %s: %w", key, err)
+	}
+
+	is.Key = resp.Key
+	is.Summary = resp.Fields.Summary
+	is.Type = resp.Fields.IssueType.Name
+	is.Status = resp.Fields.Status.Name
+	is.URL = issueURL(j.host, resp.Key)
+
+	return is, nil
+}
+
+func (j *HTTPJira) Transition(ctx context.Context, key, status string) error {
+	var resp transitionsResponse
+	err := j.do(ctx, http.MethodGet, issuePath(key)+"/transitions", nil, nil, http.StatusOK, &resp)
+	if err != nil {
+		return fmt.Errorf("can not get transitions of jira issue %s: %w", key, err)
+	}
+
+	var req transitionRequest
+	for _, t := range resp.Transitions {
+		if strings.EqualFold(t.To.Name, status) || strings.EqualFold(t.Name, status) {
+			req.Transition.ID = t.ID
+			break
+		}
+	}
+
+	if req.Transition.ID == "" {
+		return fmt.Errorf("%w: %s can not be moved to %q", jira.ErrNoTransition, key, status)
+	}
+
+	err = j.do(ctx, http.MethodPost, issuePath(key)+"/transitions", nil, req, http.StatusNoContent, nil)
+	if err != nil {
+		return fmt.Errorf("can not move jira issue %s to %q: %w", key, status, err)
+	}
+
+	return nil
+}
+
+func (j *HTTPJira) do(
+	ctx context.Context,
+	method, path string,
+	query url.Values,
+	body interface{},
+	expStatus int,
+	out interface{},
+) error {
+	hReq, err := newHTTPRequest(ctx, j.host, method, path, query, body)
+	if err != nil {
+		return err
+	}
+
+	if j.username != "" {
+		hReq.SetBasicAuth(j.username, j.token)
+	} else if j.token != "" {
+		hReq.Header.Set("Authorization", "Bearer "+j.token)
+	}
+
+	log.Ctx(ctx).Debug().
+		Str("method", method).
+		Stringer("url", hReq.URL).
+		Msg("request to jira")
+
+	hResp, err := j.c.Do(hReq)
+	if err != nil {
+		return err
+	}
+
+	defer hResp.Body.Close()
+
+	if hResp.StatusCode != expStatus {
+		return parseError(hReq, hResp)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	err = json.NewDecoder(hResp.Body).Decode(out)
+	if err != nil {
+		return fmt.Errorf("can not decode response from jira: %w", err)
+	}
+
+	return nil
+}
+
+func parseError(hReq *http.Request, hResp *http.Response) error {
+	je := jira.Error{
+		StatusCode: hResp.StatusCode,
+		Method:     hReq.Method,
+		Endpoint:   hReq.URL.Path,
+	}
+
+	body, _ := ioutil.ReadAll(hResp.Body)
+
+	var resp struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	if json.Unmarshal(body, &resp) == nil {
+		je.Messages = resp.ErrorMessages
+		for f, m := range resp.Errors {
+			je.Messages = append(je.Messages, f+": "+m)
+		}
+	}
+
+	if hResp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %v", jira.ErrNotFound, je)
+	}
+
+	return je
+}
+
+func newHTTPRequest(
+	ctx context.Context,
+	host, method, path string,
+	query url.Values,
+	body interface{},
+) (*http.Request, error) {
+	u := host + "/rest/api/2" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var br *bytes.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("can not encode request to jira: %w", err)
+		}
+
+		br = bytes.NewReader(b)
+	} else {
+		br = bytes.NewReader(nil)
+	}
+
+	hReq, err := http.NewRequestWithContext(ctx, method, u, br)
+	if err != nil {
+		return nil, fmt.Errorf("can not create request to jira: %w", err)
+	}
+
+	hReq.Header.Set("Accept", "application/json")
+	if body != nil {
+		hReq.Header.Set("Content-Type", "application/json")
+	}
+
+	return hReq, nil
+}
+
+func issuePath(key string) string {
+	return "/issue/" + url.PathEscape(key)
+}
+
+func issueURL(host, key string) string {
+	return host + "/browse/" + key
+}
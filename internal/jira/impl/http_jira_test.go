@@ -0,0 +1,88 @@
+package impl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/jira"
+)
+
+func TestHTTPJira(t *testing.T) {
+	var transitionID string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/2/issue/PROJ-1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"key":"PROJ-1","fields":{"summary":"Fix login",` +
+			`"issuetype":{"name":"Bug"},"status":{"name":"In Progress"}}}`))
+	})
+	mux.HandleFunc("/rest/api/2/issue/PROJ-1/transitions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"transitions":[` +
+				`{"id":"11","name":"Start","to":{"name":"In Progress"}},` +
+				`{"id":"21","name":"Review","to":{"name":"In Review"}}]}`))
+			return
+		}
+
+		var req transitionRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		transitionID = req.Transition.ID
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/rest/api/2/issue/PROJ-2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errorMessages":["Issue does not exist"]}`))
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	j := NewHTTPJira(config.Jira{
+		URL:   ts.URL + "/",
+		Token: "secret",
+	})
+
+	is, err := j.Issue(context.Background(), "PROJ-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := jira.Issue{
+		Key:     "PROJ-1",
+		Summary: "Fix login",
+		Type:    "Bug",
+		Status:  "In Progress",
+		URL:     ts.URL + "/browse/PROJ-1",
+	}
+	if is != exp {
+		t.Fatalf("expected issue: %+v, got: %+v", exp, is)
+	}
+
+	_, err = j.Issue(context.Background(), "PROJ-2")
+	if !errors.Is(err, jira.ErrNotFound) {
+		t.Fatal("expected ErrNotFound, got", err)
+	}
+
+	err = j.Transition(context.Background(), "PROJ-1", "in review")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if transitionID != "21" {
+		t.Fatal("wrong transition", transitionID)
+	}
+
+	err = j.Transition(context.Background(), "PROJ-1", "Done")
+	if !errors.Is(err, jira.ErrNoTransition) {
+		t.Fatal("expected ErrNoTransition, got", err)
+	}
+}
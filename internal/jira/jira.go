@@ -0,0 +1,41 @@
+// Package jira defines interface for Jira issue tracker
+package jira
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound     = errors.New("jira issue not found")
+	ErrNoTransition = errors.New("jira transition not found")
+)
+
+type Issue struct {
+	Key     string
+	Summary string
+	Type    string
+	Status  string
+	// URL is a link to issue in browser.
+	URL string
+}
+
+// Error is returned when Jira responds with unexpected status.
+type Error struct {
+	StatusCode int
+	Method     string
+	Endpoint   string
+	Messages   []string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("jira error: %s %s returned %d: %v", e.Method, e.Endpoint, e.StatusCode, e.Messages)
+}
+
+type Jira interface {
+	Issue(ctx context.Context, key string) (Issue, error)
+	// Transition moves issue to status, transition is chosen by
+	// its name or name of target status.
+	Transition(ctx context.Context, key, status string) error
+}
@@ -6,6 +6,7 @@ import (
 
 	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/glmt"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/notifier"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/team"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/templating"
 
@@ -18,11 +19,12 @@ const (
 	mattermostDefaultMessageTmpl = "@here\n{{.Description}}\n{{.MergeRequestURL}}"
 )
 
-func NewMattermostWebHookNotifier(cfg config.MattermostWebHook) *MattermostWebHookNotifier {
+func NewMattermostWebHookNotifier(cfg config.MattermostWebHook, lenient bool) *MattermostWebHookNotifier {
 	return &MattermostWebHookNotifier{
 		url:         cfg.URL,
 		user:        cfg.User,
 		messageTpml: cfg.MessageTmpl,
+		lenient:     lenient,
 	}
 }
 
@@ -30,9 +32,32 @@ type MattermostWebHookNotifier struct {
 	url         string
 	user        string
 	messageTpml string
+	lenient     bool
 }
 
-func (mn *MattermostWebHookNotifier) Send(ctx context.Context, args map[string]string, add string, mentions []*team.Member) error {
+func (mn *MattermostWebHookNotifier) Send(ctx context.Context, args map[string]interface{}, add string, mentions []*team.Member) error {
+	m, err := mn.message(args, add, mentions)
+	if err != nil {
+		return err
+	}
+
+	message := matterhook.Message{
+		Text:     m,
+		Username: mn.user,
+	}
+	return matterhook.Send(mn.url, message)
+}
+
+func (mn *MattermostWebHookNotifier) Render(args map[string]interface{}, add string, mentions []*team.Member) ([]notifier.Message, error) {
+	m, err := mn.message(args, add, mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	return []notifier.Message{{Notifier: "mattermost_web_hook", Text: m}}, nil
+}
+
+func (mn *MattermostWebHookNotifier) message(args map[string]interface{}, add string, mentions []*team.Member) (string, error) {
 	templ := mn.messageTpml
 	if templ == "" {
 		templ = mattermostDefaultMessageTmpl
@@ -40,17 +65,16 @@ func (mn *MattermostWebHookNotifier) Send(ctx context.Context, args map[string]s
 
 	args[glmt.TmpVarNotificationMentions] = getMattermostMentions(mentions)
 
-	m := templating.CreateText("mattermost_wh_message", templ, args)
+	m, err := templating.CreateText("mattermost_wh_message", templ, args, mn.lenient)
+	if err != nil {
+		return "", err
+	}
 
 	if add != "" {
 		m += "\n" + add
 	}
 
-	message := matterhook.Message{
-		Text:     m,
-		Username: mn.user,
-	}
-	return matterhook.Send(mn.url, message)
+	return m, nil
 }
 
 func getMattermostMentions(mentions []*team.Member) string {
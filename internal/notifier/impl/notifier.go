@@ -29,7 +29,7 @@ func NewMultiNotifier(notifiers ...notifier.Notifier) *MultiNotifier {
 
 func (mn *MultiNotifier) Send(
 	ctx context.Context,
-	args map[string]string,
+	args map[string]interface{},
 	add string,
 	mentions []*team.Member,
 ) (err error) {
@@ -48,6 +48,20 @@ func (mn *MultiNotifier) Send(
 	return nil
 }
 
+func (mn *MultiNotifier) Render(args map[string]interface{}, add string, mentions []*team.Member) ([]notifier.Message, error) {
+	var msgs []notifier.Message
+	for _, n := range mn.notifiers {
+		m, err := n.Render(args, add, mentions)
+		if err != nil {
+			return msgs, fmt.Errorf("multi render: %T: %w", n, err)
+		}
+
+		msgs = append(msgs, m...)
+	}
+
+	return msgs, nil
+}
+
 func getMentions(mentions []*team.Member, memberKey string, format string) string {
 	ms := make([]string, 0, len(mentions))
 	for _, m := range mentions {
@@ -5,17 +5,19 @@ import (
 
 	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/glmt"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/notifier"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/team"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/templating"
 
 	"github.com/slack-go/slack"
 )
 
-func NewSlackWebHookNotifier(cfg config.SlackWebHook) *SlackWebHookNotifier {
+func NewSlackWebHookNotifier(cfg config.SlackWebHook, lenient bool) *SlackWebHookNotifier {
 	return &SlackWebHookNotifier{
 		url:         cfg.URL,
 		user:        cfg.User,
 		messageTmpl: cfg.MessageTmpl,
+		lenient:     lenient,
 	}
 }
 
@@ -23,9 +25,32 @@ type SlackWebHookNotifier struct {
 	url         string
 	user        string
 	messageTmpl string
+	lenient     bool
 }
 
-func (sn *SlackWebHookNotifier) Send(ctx context.Context, args map[string]string, add string, mentions []*team.Member) error {
+func (sn *SlackWebHookNotifier) Send(ctx context.Context, args map[string]interface{}, add string, mentions []*team.Member) error {
+	m, err := sn.message(args, add, mentions)
+	if err != nil {
+		return err
+	}
+
+	msg := &slack.WebhookMessage{
+		Text:     m,
+		Username: sn.user,
+	}
+	return slack.PostWebhookContext(ctx, sn.url, msg)
+}
+
+func (sn *SlackWebHookNotifier) Render(args map[string]interface{}, add string, mentions []*team.Member) ([]notifier.Message, error) {
+	m, err := sn.message(args, add, mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	return []notifier.Message{{Notifier: "slack_web_hook", Text: m}}, nil
+}
+
+func (sn *SlackWebHookNotifier) message(args map[string]interface{}, add string, mentions []*team.Member) (string, error) {
 	templ := sn.messageTmpl
 	if templ == "" {
 		templ = "<!here>\n{{.Description}}\n{{.MergeRequestURL}}"
@@ -37,15 +62,14 @@ func (sn *SlackWebHookNotifier) Send(ctx context.Context, args map[string]string
 		"<@%s>",
 	)
 
-	m := templating.CreateText("slack_wh_message", templ, args)
+	m, err := templating.CreateText("slack_wh_message", templ, args, sn.lenient)
+	if err != nil {
+		return "", err
+	}
 
 	if add != "" {
 		m += "\n" + add
 	}
 
-	msg := &slack.WebhookMessage{
-		Text:     m,
-		Username: sn.user,
-	}
-	return slack.PostWebhookContext(ctx, sn.url, msg)
+	return m, nil
 }
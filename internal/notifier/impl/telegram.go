@@ -8,6 +8,7 @@ import (
 
 	"gitlab.com/gitlab-merge-tool/glmt/internal/config"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/glmt"
+	"gitlab.com/gitlab-merge-tool/glmt/internal/notifier"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/team"
 	"gitlab.com/gitlab-merge-tool/glmt/internal/templating"
 )
@@ -17,7 +18,7 @@ const (
 	telegramSendMessagePath = "bot%s/sendMessage"
 )
 
-func NewTelegramNotifier(cfg config.Telegram) *TelegramNotifier {
+func NewTelegramNotifier(cfg config.Telegram, lenient bool) *TelegramNotifier {
 	const defaultMessageTmpl = "{{.Description}}\n{{.MergeRequestURL}}"
 
 	if cfg.MessageTmpl == "" {
@@ -29,6 +30,7 @@ func NewTelegramNotifier(cfg config.Telegram) *TelegramNotifier {
 		apiKey:      cfg.APIKey,
 		messageTmpl: cfg.MessageTmpl,
 		chatID:      cfg.ChatID,
+		lenient:     lenient,
 
 		httpClient: http.DefaultClient,
 	}
@@ -39,26 +41,20 @@ type TelegramNotifier struct {
 	apiKey      string
 	chatID      string
 	messageTmpl string
+	lenient     bool
 
 	httpClient *http.Client
 }
 
 func (tn *TelegramNotifier) Send(
 	ctx context.Context,
-	args map[string]string,
+	args map[string]interface{},
 	add string,
 	mentions []*team.Member,
 ) error {
-	args[glmt.TmpVarNotificationMentions] = getMentions(
-		mentions,
-		memberKeyTelegram,
-		"@%s",
-	)
-
-	m := templating.CreateText("telegram_wh_message", tn.messageTmpl, args)
-
-	if add != "" {
-		m += "\n" + add
+	m, err := tn.message(args, add, mentions)
+	if err != nil {
+		return err
 	}
 
 	u, err := url.Parse(tn.url)
@@ -89,3 +85,31 @@ func (tn *TelegramNotifier) Send(
 
 	return nil
 }
+
+func (tn *TelegramNotifier) Render(args map[string]interface{}, add string, mentions []*team.Member) ([]notifier.Message, error) {
+	m, err := tn.message(args, add, mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	return []notifier.Message{{Notifier: "telegram", Text: m}}, nil
+}
+
+func (tn *TelegramNotifier) message(args map[string]interface{}, add string, mentions []*team.Member) (string, error) {
+	args[glmt.TmpVarNotificationMentions] = getMentions(
+		mentions,
+		memberKeyTelegram,
+		"@%s",
+	)
+
+	m, err := templating.CreateText("telegram_wh_message", tn.messageTmpl, args, tn.lenient)
+	if err != nil {
+		return "", err
+	}
+
+	if add != "" {
+		m += "\n" + add
+	}
+
+	return m, nil
+}
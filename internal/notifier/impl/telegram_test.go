@@ -53,10 +53,10 @@ func TestTelegramNotifier_Send(t *testing.T) {
 
 	cfg.URL = ts.URL
 
-	tm := impl.NewTelegramNotifier(cfg)
+	tm := impl.NewTelegramNotifier(cfg, false)
 	err := tm.Send(
 		context.Background(),
-		map[string]string{
+		map[string]interface{}{
 			glmt.TmpVarDescription: description,
 		},
 		addText,
@@ -7,5 +7,14 @@ import (
 )
 
 type Notifier interface {
-	Send(ctx context.Context, args map[string]string, add string, mentions []*team.Member) error
+	Send(ctx context.Context, args map[string]interface{}, add string, mentions []*team.Member) error
+	// Render renders messages Send would send without sending them.
+	Render(args map[string]interface{}, add string, mentions []*team.Member) ([]Message, error)
+}
+
+// Message is a rendered notification message.
+type Message struct {
+	// Notifier is a name of notifier in config.
+	Notifier string `json:"notifier"`
+	Text     string `json:"text"`
 }
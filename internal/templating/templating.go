@@ -2,28 +2,228 @@ package templating
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 	"text/template"
+	"text/template/parse"
 	"unicode"
 )
 
-func CreateText(part, format string, args map[string]string) string {
+var ErrUnknownVariable = errors.New("unknown template variable")
+
+// CreateText renders template with args. Errors of parsing and rendering, including
+// references to args missing in map, are returned with position in template.
+// With lenient errors are ignored and missing args are rendered empty.
+func CreateText(part, format string, args map[string]interface{}, lenient bool) (string, error) {
 	if format == "" {
-		return ""
+		return "", nil
+	}
+
+	tmpl, err := template.New(part).Funcs(funcMap()).Parse(format)
+	if err != nil {
+		if lenient {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("can not parse %s template: %w", part, err)
+	}
+
+	buff := &bytes.Buffer{}
+
+	if lenient {
+		_ = tmpl.Execute(buff, withMissing(tmpl, args))
+		return buff.String(), nil
+	}
+
+	err = tmpl.Option("missingkey=error").Execute(buff, args)
+	if err != nil {
+		return "", fmt.Errorf("can not render %s template: %w", part, err)
+	}
+
+	return buff.String(), nil
+}
+
+// Validate parses template and checks that it uses only known top level variables.
+func Validate(part, format string, known []string) error {
+	tmpl, err := template.New(part).Funcs(funcMap()).Parse(format)
+	if err != nil {
+		return fmt.Errorf("can not parse %s template: %w", part, err)
+	}
+
+	used := map[string]bool{}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			addRootFields(t.Tree.Root, true, used)
+		}
+	}
+
+	var unknown []string
+	for f := range used {
+		if !contains(known, f) {
+			unknown = append(unknown, f)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("%w in %s template: %s", ErrUnknownVariable, part, strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+// Render renders template with any data, unlike CreateText errors of
+// template are returned.
+func Render(part, format string, data interface{}) (string, error) {
+	tmpl, err := template.New(part).Funcs(funcMap()).Parse(format)
+	if err != nil {
+		return "", fmt.Errorf("can not parse %s template: %w", part, err)
+	}
+
+	buff := &bytes.Buffer{}
+	err = tmpl.Execute(buff, data)
+	if err != nil {
+		return "", fmt.Errorf("can not render %s template: %w", part, err)
 	}
 
-	funcMap := template.FuncMap{
+	return buff.String(), nil
+}
+
+func funcMap() template.FuncMap {
+	return template.FuncMap{
 		"humanizeText": humanizeText,
 		"upper":        strings.ToUpper,
 		"lower":        strings.ToLower,
 	}
+}
 
-	tmpl, _ := template.New(part).Funcs(funcMap).Parse(format)
+// withMissing returns copy of args with empty strings for absent top level fields
+// used in template, so they are rendered empty instead of "<no value>".
+func withMissing(tmpl *template.Template, args map[string]interface{}) map[string]interface{} {
+	r := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		r[k] = v
+	}
 
-	buff := &bytes.Buffer{}
-	_ = tmpl.Execute(buff, args)
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			addFields(t.Tree.Root, r)
+		}
+	}
+
+	return r
+}
 
-	return buff.String()
+func addFields(node parse.Node, args map[string]interface{}) {
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		if _, ok := args[n.Ident[0]]; !ok {
+			args[n.Ident[0]] = ""
+		}
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, c := range n.Nodes {
+			addFields(c, args)
+		}
+	case *parse.ActionNode:
+		addFields(n.Pipe, args)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+
+		for _, c := range n.Cmds {
+			addFields(c, args)
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			addFields(a, args)
+		}
+	case *parse.ChainNode:
+		addFields(n.Node, args)
+	case *parse.IfNode:
+		addBranchFields(&n.BranchNode, args)
+	case *parse.RangeNode:
+		addBranchFields(&n.BranchNode, args)
+	case *parse.WithNode:
+		addBranchFields(&n.BranchNode, args)
+	case *parse.TemplateNode:
+		addFields(n.Pipe, args)
+	}
+}
+
+func addBranchFields(n *parse.BranchNode, args map[string]interface{}) {
+	addFields(n.Pipe, args)
+	addFields(n.List, args)
+	addFields(n.ElseList, args)
+}
+
+// addRootFields adds top level fields used in node, root tells if dot is
+// template's data. Dot of range and with blocks is not, but $ always is.
+func addRootFields(node parse.Node, root bool, fields map[string]bool) {
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		if root {
+			fields[n.Ident[0]] = true
+		}
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" && len(n.Ident) > 1 {
+			fields[n.Ident[1]] = true
+		}
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, c := range n.Nodes {
+			addRootFields(c, root, fields)
+		}
+	case *parse.ActionNode:
+		addRootFields(n.Pipe, root, fields)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+
+		for _, c := range n.Cmds {
+			addRootFields(c, root, fields)
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			addRootFields(a, root, fields)
+		}
+	case *parse.ChainNode:
+		addRootFields(n.Node, root, fields)
+	case *parse.IfNode:
+		addRootFields(n.Pipe, root, fields)
+		addRootFields(n.List, root, fields)
+		addRootFields(n.ElseList, root, fields)
+	case *parse.RangeNode:
+		addRootFields(n.Pipe, root, fields)
+		addRootFields(n.List, false, fields)
+		addRootFields(n.ElseList, root, fields)
+	case *parse.WithNode:
+		addRootFields(n.Pipe, root, fields)
+		addRootFields(n.List, false, fields)
+		addRootFields(n.ElseList, root, fields)
+	case *parse.TemplateNode:
+		addRootFields(n.Pipe, root, fields)
+	}
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
 }
 
 func isSeparator(r rune) bool {
@@ -0,0 +1,80 @@
+package templating
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCreateText(t *testing.T) {
+	type commit struct {
+		Subject string
+	}
+
+	args := map[string]interface{}{
+		"Task":    "TASK-1",
+		"Commits": []commit{{Subject: "first"}, {Subject: "second"}},
+		"Count":   2,
+	}
+
+	format := "{{.Task}} {{.Missing}}{{if .Other}}other{{end}}: {{.Count}}\n" +
+		"{{range .Commits}}- {{.Subject}}\n{{end}}"
+
+	exp := "TASK-1 : 2\n- first\n- second\n"
+	got, err := CreateText("test", format, args, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != exp {
+		t.Fatalf("exp: %q, got: %q", exp, got)
+	}
+
+	if _, ok := args["Missing"]; ok {
+		t.Fatal("args should not be modified")
+	}
+}
+
+func TestCreateText_Strict(t *testing.T) {
+	args := map[string]interface{}{
+		"Task": "TASK-1",
+	}
+
+	got, err := CreateText("title", "{{.Task}} {{upper .Task}}", args, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != "TASK-1 TASK-1" {
+		t.Fatalf("wrong text: %q", got)
+	}
+
+	_, err = CreateText("title", "{{.Tsk}}", args, false)
+	if err == nil || !strings.Contains(err.Error(), "Tsk") {
+		t.Fatal("exp error for missing variable, got", err)
+	}
+
+	_, err = CreateText("title", "{{.Task", args, false)
+	if err == nil || !strings.Contains(err.Error(), "title:1") {
+		t.Fatal("exp parse error with position, got", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	known := []string{"Task", "Commits"}
+
+	err := Validate("description", "{{.Task}}{{range .Commits}}{{.Subject}}{{$.Task}}{{end}}", known)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Validate("description", "{{.Tsk}}{{with .Commits}}{{$.Other}}{{end}}", known)
+	if !errors.Is(err, ErrUnknownVariable) || !strings.Contains(err.Error(), "Other, Tsk") {
+		t.Fatal("exp unknown variables error, got", err)
+	}
+
+	err = Validate("description", "{{if .Task}}", known)
+	if err == nil {
+		t.Fatal("exp parse error")
+	}
+}